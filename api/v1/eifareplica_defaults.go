@@ -0,0 +1,45 @@
+/*
+Copyright 2025 Erfan Mahvash.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	DefaultActiveDeadlineSeconds = int64(15)
+	DefaultBackoffLimit          = int32(1)
+)
+
+// DefaultJobSpec sets the deadline, backoff and restart policy of the job template when they are unset.
+// The defaulting webhook persists them, the controller applies them again to the EifaReplicas created
+// before the webhook was installed or while it is disabled. Never is the only restart policy admitted,
+// a pod restarted within its job would hide the failures of the job from the retries.
+func DefaultJobSpec(jobSpec *batchv1.JobSpec) {
+	if jobSpec.ActiveDeadlineSeconds == nil {
+		activeDeadlineSeconds := DefaultActiveDeadlineSeconds
+		jobSpec.ActiveDeadlineSeconds = &activeDeadlineSeconds
+	}
+	if jobSpec.BackoffLimit == nil {
+		backoffLimit := DefaultBackoffLimit
+		jobSpec.BackoffLimit = &backoffLimit
+	}
+	if jobSpec.Template.Spec.RestartPolicy == "" {
+		jobSpec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+}
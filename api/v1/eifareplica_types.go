@@ -26,9 +26,15 @@ import (
 
 // EifaReplicaSpec defines the desired state of EifaReplica
 type ScaleTargetRef struct {
-	// +kubebuilder:validation:Enum={"Deployment","deployment","deploy","Deploy"}
+	// +kubebuilder:validation:Enum={"Deployment","deployment","deploy","Deploy","HorizontalPodAutoscaler","horizontalpodautoscaler","hpa","HPA","ScaledObject","scaledobject"}
 	Kind string `json:"kind"`
 	Name string `json:"name"`
+
+	// Mode selects which bound of an autoscaler target is driven by the job result,
+	// it is ignored for Deployments.
+	// +kubebuilder:validation:Enum=MinReplicas;MaxReplicas
+	// +optional
+	Mode string `json:"mode,omitempty"`
 }
 type EifaReplicaSpec struct {
 	ScaleTargetRef ScaleTargetRef `json:"scaleTargetRef"`
@@ -42,20 +48,563 @@ type EifaReplicaSpec struct {
 	// +kubebuilder:validation:Pattern=`^(@(annually|yearly|monthly|weekly|daily|hourly|reboot))|(@every (\d+(ns|us|µs|ms|s|m|h))+)|((((\d+,)+\d+|(\d+(\/|-)\d+)|\d+|\*) ?){5,7})$`
 	Schedule    string                  `json:"schedule"`
 	JobTemplate batchv1.JobTemplateSpec `json:"jobTemplate" protobuf:"bytes,1,opt,name=jobTemplate"`
+
+	// LeadTime runs the job ahead of every scheduled time, its result is held and applied at the
+	// scheduled time, so the change does not land late by the runtime of the job. It must be shorter
+	// than the interval between two scheduled times.
+	// +optional
+	LeadTime *metav1.Duration `json:"leadTime,omitempty"`
+
+	// Precedence resolves conflicts with HorizontalPodAutoscalers and other EifaReplicas
+	// scaling the same target or the workload behind it. When it is unset replicas are not written while a conflict exists,
+	// otherwise this EifaReplica wins over HPAs and over EifaReplicas with a lower or unset precedence.
+	// +optional
+	Precedence *int32 `json:"precedence,omitempty"`
+
+	// Priority arbitrates the EifaReplicaQuotas of the namespace: when a budget can not hold every
+	// desired replica, EifaReplicas with a higher priority get theirs first and the lower ones are trimmed.
+	// Defaults to 0.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+
+	// Mode selects what is done with the desired replicas. Active writes them to the target,
+	// Metric only publishes them through the external.metrics.k8s.io API for an HPA to consume,
+	// Shadow runs the whole pipeline but only records the replicas it would have written.
+	// +kubebuilder:validation:Enum=Active;Metric;Shadow
+	// +kubebuilder:default=Active
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// Behavior configures the scaling behavior of the target in the up and down directions
+	// +optional
+	Behavior *EifaReplicaBehavior `json:"behavior,omitempty"`
+
+	// Fallback configures the replicas applied when the job keeps failing, the target is left
+	// untouched until the next successful job when it is unset
+	// +optional
+	Fallback *Fallback `json:"fallback,omitempty"`
+
+	// RetryPolicy re-runs a failed job within the same schedule slot instead of waiting for the next one
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// CircuitBreaker stops running the job of a chronically failing EifaReplica
+	// +optional
+	CircuitBreaker *CircuitBreaker `json:"circuitBreaker,omitempty"`
+
+	// MaxResultAge is the age after which the last successful job result is stale, the target is then
+	// reverted to the baseline replicas until the next successful job. A job printing a JSON object
+	// {"replicas": N, "ttlSeconds": S} can shorten it for its own result.
+	// +optional
+	MaxResultAge *metav1.Duration `json:"maxResultAge,omitempty"`
+
+	// BaselineReplicas are applied once the last job result is stale, defaults to the min replicas
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BaselineReplicas *int32 `json:"baselineReplicas,omitempty"`
+
+	// AnomalyDetection rejects job outputs deviating too much from the previously accepted ones
+	// +optional
+	AnomalyDetection *AnomalyDetection `json:"anomalyDetection,omitempty"`
+
+	// Approval makes large changes of the target replicas wait for a human approval, it is ignored
+	// in Metric and Shadow modes
+	// +optional
+	Approval *Approval `json:"approval,omitempty"`
+
+	// MaxHourlyCost caps the replicas so the estimated hourly cost of the target stays below it, the price
+	// table is read from the ConfigMap given to the operator. It never caps below the min replicas and is
+	// ignored in Metric mode.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	MaxHourlyCost *string `json:"maxHourlyCost,omitempty"`
+
+	// Verify waits for the pods of a scale up to become ready, it is ignored in Metric and Shadow modes
+	// and for the max replicas of an autoscaler
+	// +optional
+	Verify *Verify `json:"verify,omitempty"`
+
+	// WaitForRollout defers the changes of the replicas while the deployment behind the target is rolling
+	// out a new pod template, they are retried once the rollout is complete
+	// +optional
+	WaitForRollout bool `json:"waitForRollout,omitempty"`
+
+	// Prewarm runs low priority placeholder pods for a lead time before a large scale up is written, so
+	// the cluster autoscaler provisions their nodes in advance. Only the scale ups known ahead are pre-warmed:
+	// the results held by the lead time of the job and the points of a timeline. It is ignored in Metric and
+	// Shadow modes and for the max replicas of an autoscaler.
+	// +optional
+	Prewarm *Prewarm `json:"prewarm,omitempty"`
+}
+
+// Prewarm configures the placeholder pods provisioning the capacity of a scale up in advance
+type Prewarm struct {
+	// LeadSeconds is how long before the scale up is written the placeholder pods are started, it is
+	// also how long they are kept afterwards for the pods of the target to land
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=300
+	// +optional
+	LeadSeconds int32 `json:"leadSeconds,omitempty"`
+
+	// MinReplicaChange is the smallest scale up pre-warmed, smaller ones are written right away
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	MinReplicaChange int32 `json:"minReplicaChange,omitempty"`
+
+	// PriorityClassName is the PriorityClass of the placeholder pods, its value should be lower than
+	// the one of any workload so the placeholders are preempted as soon as the capacity is needed
+	// +kubebuilder:validation:MinLength=1
+	PriorityClassName string `json:"priorityClassName"`
+
+	// Image is the image of the placeholder containers
+	// +kubebuilder:default="registry.k8s.io/pause:3.10"
+	// +optional
+	Image string `json:"image,omitempty"`
+}
+
+// Verify configures the readiness check following a scale up of the target
+type Verify struct {
+	// TimeoutSeconds is how long the ready replicas of the deployment behind the target may take to
+	// reach the written replicas
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=300
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// OnFailure is what is done when the timeout is reached: Rollback restores the replicas written
+	// before the scale up, Report only sets the Degraded condition
+	// +kubebuilder:validation:Enum=Rollback;Report
+	// +kubebuilder:default=Report
+	// +optional
+	OnFailure string `json:"onFailure,omitempty"`
 }
 
+// Approval configures which changes of the target replicas require an approval, a change requires
+// it when it reaches any of the thresholds. A proposal is approved or rejected by setting the
+// schedule.eifa.org/approve or schedule.eifa.org/reject annotation to its id, and expires at the
+// next schedule slot.
+type Approval struct {
+	// MinReplicaChange is the smallest change, in replicas, requiring an approval
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicaChange *int32 `json:"minReplicaChange,omitempty"`
+
+	// MinPercentChange is the smallest change, in percent of the current replicas, requiring an approval
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinPercentChange *int32 `json:"minPercentChange,omitempty"`
+}
+
+// AnomalyDetection compares every job output with the median of the last accepted outputs,
+// a rejected output is recorded in the status instead of being applied
+type AnomalyDetection struct {
+	// HistorySize is the number of accepted outputs whose median is compared with a new output,
+	// no output is rejected until that many outputs were accepted
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	HistorySize int32 `json:"historySize"`
+
+	// MaxDeviationFactor is the factor by which an output may exceed the median or fall below it,
+	// e.g. "3" rejects outputs above three times or below a third of the median. The median is taken as at least 1.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	MaxDeviationFactor string `json:"maxDeviationFactor"`
+
+	// LevelShiftThreshold is the number of consecutive rejected outputs accepted as a shift of the level
+	// once they deviate from their own median by less than the max deviation factor, they replace the
+	// accepted outputs
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	// +optional
+	LevelShiftThreshold int32 `json:"levelShiftThreshold,omitempty"`
+}
+
+// CircuitBreaker opens after consecutive failed schedule slots, no job is run while it is open.
+// The first job run once it is over is a probe, the circuit closes when it succeeds and opens
+// again for a longer interval when it fails.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failed schedule slots opening the circuit
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold int32 `json:"failureThreshold"`
+
+	// OpenSeconds is how long the circuit stays open the first time, it doubles on every failed probe
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=600
+	// +optional
+	OpenSeconds int32 `json:"openSeconds,omitempty"`
+
+	// MaxOpenSeconds caps how long the circuit stays open
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxOpenSeconds *int32 `json:"maxOpenSeconds,omitempty"`
+}
+
+// RetryPolicy configures how a failed job is retried, a retry never runs past the next scheduled time
+type RetryPolicy struct {
+	// MaxRetries is the number of times a failed job is re-run within a schedule slot
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=20
+	MaxRetries int32 `json:"maxRetries"`
+
+	// BackoffSeconds is the delay before the first retry, it doubles on every retry
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	// +optional
+	BackoffSeconds int32 `json:"backoffSeconds,omitempty"`
+
+	// MaxBackoffSeconds caps the delay between two retries
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxBackoffSeconds *int32 `json:"maxBackoffSeconds,omitempty"`
+}
+
+// Fallback configures the replicas applied after consecutive job failures,
+// exactly one of replicas, keepCurrent and scaleToMax must be set
+type Fallback struct {
+	// AfterConsecutiveFailures is the number of consecutive job failures after which the fallback is applied
+	// +kubebuilder:validation:Minimum=1
+	AfterConsecutiveFailures int32 `json:"afterConsecutiveFailures"`
+
+	// Replicas is applied as if it was the job output
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// KeepCurrent keeps the target replicas, cancelling any ramp or deferred change in progress
+	// +optional
+	KeepCurrent bool `json:"keepCurrent,omitempty"`
+
+	// ScaleToMax applies the max replicas
+	// +optional
+	ScaleToMax bool `json:"scaleToMax,omitempty"`
+}
+
+// EifaReplicaBehavior configures the scaling behavior of the target in the up and down directions
+type EifaReplicaBehavior struct {
+	// ScaleUp is the scaling policy for scaling up
+	// +optional
+	ScaleUp *ScalingRules `json:"scaleUp,omitempty"`
+	// ScaleDown is the scaling policy for scaling down
+	// +optional
+	ScaleDown *ScalingRules `json:"scaleDown,omitempty"`
+	// Ramp spreads large changes of the target replicas over several writes,
+	// it is ignored in Metric mode
+	// +optional
+	Ramp *RampConfig `json:"ramp,omitempty"`
+}
+
+// RampConfig configures how a change of the target replicas is split into steps
+type RampConfig struct {
+	// Steps is the number of writes a change is split into
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=100
+	Steps int32 `json:"steps"`
+
+	// DurationSeconds is the time between the first and the last write of a ramp
+	// +kubebuilder:validation:Minimum=1
+	DurationSeconds int32 `json:"durationSeconds"`
+
+	// MinReplicaChange is the smallest change which is ramped, smaller changes are written at once
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReplicaChange int32 `json:"minReplicaChange,omitempty"`
+}
+
+// ScalingRules configures the scaling behavior for one direction
+type ScalingRules struct {
+	// StabilizationWindowSeconds is the number of seconds for which past job outputs are considered
+	// while scaling. The lowest output of the window is applied when scaling up and the highest one
+	// when scaling down, so a noisy job does not make the target flap.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=86400
+	// +optional
+	StabilizationWindowSeconds *int32 `json:"stabilizationWindowSeconds,omitempty"`
+
+	// SelectPolicy selects which policy is applied when several are set, Max allows the highest
+	// change, Min the lowest one and Disabled prevents scaling in this direction. Defaults to Max.
+	// +kubebuilder:validation:Enum=Max;Min;Disabled
+	// +optional
+	SelectPolicy *string `json:"selectPolicy,omitempty"`
+
+	// Policies limit the change of the target replicas per period, the change is not limited when empty
+	// +listType=atomic
+	// +optional
+	Policies []ScalingPolicy `json:"policies,omitempty"`
+
+	// CooldownSeconds defers any scale action, in either direction, following a scale action in
+	// this direction. It is ignored in Metric mode.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=86400
+	// +optional
+	CooldownSeconds *int32 `json:"cooldownSeconds,omitempty"`
+}
+
+// ScalingPolicy limits the change of the target replicas within a period
+type ScalingPolicy struct {
+	// Type is Pods to limit the change to a number of replicas, or Percent to limit it
+	// to a percentage of the replicas at the start of the period
+	// +kubebuilder:validation:Enum=Pods;Percent
+	Type string `json:"type"`
+
+	// Value is the amount of change allowed by the policy
+	// +kubebuilder:validation:Minimum=1
+	Value int32 `json:"value"`
+
+	// PeriodSeconds is the window of time the policy applies to
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	PeriodSeconds int32 `json:"periodSeconds"`
+}
+
+const (
+	JOB_SUCCESS      = "Job-Success"
+	JOB_FAILED       = "Job-Failed"
+	JOB_RUNNING      = "Job-Running"
+	FAILED           = "Failed"
+	SUCCESS          = "Success"
+	CONFLICT         = "Conflict"
+	CIRCUIT_OPEN     = "CircuitOpen"
+	REJECTED_OUTPUT  = "RejectedOutput"
+	PENDING_APPROVAL = "PendingApproval"
+	QUOTA_LIMITED    = "QuotaLimited"
+	COST_LIMITED     = "CostLimited"
+	PROGRESSING      = "Progressing"
+	DEGRADED         = "Degraded"
+	WAITING_ROLLOUT  = "WaitingForRollout"
+	PREWARMING       = "Prewarming"
+	RESULT_PENDING   = "ResultPending"
+	TIMELINE         = "Timeline"
+)
+
+const (
+	VERIFY_ROLLBACK = "Rollback"
+	VERIFY_REPORT   = "Report"
+)
+
+const (
+	APPROVE_ANNOTATION = "schedule.eifa.org/approve"
+	REJECT_ANNOTATION  = "schedule.eifa.org/reject"
+)
+
+const (
+	MODE_MIN_REPLICAS = "MinReplicas"
+	MODE_MAX_REPLICAS = "MaxReplicas"
+	MODE_ACTIVE       = "Active"
+	MODE_METRIC       = "Metric"
+	MODE_SHADOW       = "Shadow"
+)
+
 const (
-	JOB_SUCCESS = "Job-Success"
-	JOB_FAILED  = "Job-Failed"
-	JOB_RUNNING = "Job-Running"
-	FAILED      = "Failed"
-	SUCCESS     = "Success"
+	POLICY_PODS     = "Pods"
+	POLICY_PERCENT  = "Percent"
+	SELECT_MAX      = "Max"
+	SELECT_MIN      = "Min"
+	SELECT_DISABLED = "Disabled"
+	DIRECTION_UP    = "Up"
+	DIRECTION_DOWN  = "Down"
 )
 
 // EifaReplicaStatus defines the observed state of EifaReplica
 type EifaReplicaStatus struct {
 	Conditions         []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 	NextTransitionTime string             `json:"nextTransitionTime,omitempty"`
+
+	// LastJobOutput is the raw replica count printed by the last successful job
+	// +optional
+	LastJobOutput *int32 `json:"lastJobOutput,omitempty"`
+	// Verification is the scale up whose readiness is being verified
+	// +optional
+	Verification *Verification `json:"verification,omitempty"`
+	// Prewarm is the scale up whose capacity is provisioned by placeholder pods
+	// +optional
+	Prewarm *PrewarmStatus `json:"prewarm,omitempty"`
+	// PendingResult is the result of a job run ahead of its scheduled time, held until then
+	// +optional
+	PendingResult *PendingResult `json:"pendingResult,omitempty"`
+	// Timeline is the replica timeline printed by the last successful job, its points are applied
+	// at their time until the next job replaces it
+	// +optional
+	Timeline *TimelineStatus `json:"timeline,omitempty"`
+	// Allocation explains the replicas allowed by the EifaReplicaQuotas of the namespace, it is only
+	// set while they are lower than the desired replicas
+	// +optional
+	Allocation *QuotaAllocation `json:"allocation,omitempty"`
+	// ShadowReplicas are the replicas which would have been written to the target in Shadow mode
+	// +optional
+	ShadowReplicas *int32 `json:"shadowReplicas,omitempty"`
+	// HourlyCost is the estimated hourly cost of the target at the replicas last written,
+	// or which would have been written in Shadow mode
+	// +optional
+	HourlyCost string `json:"hourlyCost,omitempty"`
+	// RecommendedReplicas is the last job output clamped to the min and max replicas
+	// and stabilized, before the scaling policies are applied
+	// +optional
+	RecommendedReplicas *int32 `json:"recommendedReplicas,omitempty"`
+	// DesiredReplicas is the replica count decided for the last job output,
+	// after clamping to the min and max replicas and applying the scaling behavior
+	// +optional
+	DesiredReplicas *int32 `json:"desiredReplicas,omitempty"`
+	// LastSuccessfulRunTime is the time the last successful job finished
+	// +optional
+	LastSuccessfulRunTime *metav1.Time `json:"lastSuccessfulRunTime,omitempty"`
+	// AcceptedOutputs are the last accepted job outputs, used to detect anomalous outputs
+	// +optional
+	AcceptedOutputs []int32 `json:"acceptedOutputs,omitempty"`
+	// RejectedOutput is the raw value of the last job output rejected as anomalous,
+	// it is cleared once an output is accepted
+	// +optional
+	RejectedOutput *int32 `json:"rejectedOutput,omitempty"`
+	// RejectedOutputs are the raw values of the consecutive job outputs rejected as anomalous,
+	// they are accepted as a shift of the level once they agree with each other
+	// +optional
+	RejectedOutputs []int32 `json:"rejectedOutputs,omitempty"`
+	// ResultTTLSeconds is the time to live reported by the last successful job
+	// +optional
+	ResultTTLSeconds *int32 `json:"resultTTLSeconds,omitempty"`
+	// Stale is set once the target was reverted to the baseline replicas because the last job result is stale
+	// +optional
+	Stale bool `json:"stale,omitempty"`
+	// ConsecutiveFailures is the number of schedule slots whose jobs failed since the last successful job
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+	// SlotEndTime is the scheduled time ending the current schedule slot
+	// +optional
+	SlotEndTime *metav1.Time `json:"slotEndTime,omitempty"`
+	// Attempts is the number of jobs run within the current schedule slot
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+	// CircuitOpenUntil is the end of the interval the circuit breaker is open for
+	// +optional
+	CircuitOpenUntil *metav1.Time `json:"circuitOpenUntil,omitempty"`
+	// CircuitTrips is the number of times the circuit breaker opened since it was last closed
+	// +optional
+	CircuitTrips int32 `json:"circuitTrips,omitempty"`
+	// Recommendations are the clamped job outputs within the largest stabilization window
+	// +optional
+	Recommendations []ReplicaRecommendation `json:"recommendations,omitempty"`
+	// ScaleEvents are the changes of the target replicas within the longest scaling policy period
+	// +optional
+	ScaleEvents []ScaleEvent `json:"scaleEvents,omitempty"`
+	// Ramp is the progress of the ramp to the desired replicas, it is re-planned on every job result
+	// +optional
+	Ramp *RampStatus `json:"ramp,omitempty"`
+	// PendingApproval is the change of the target replicas waiting for an approval
+	// +optional
+	PendingApproval *ApprovalProposal `json:"pendingApproval,omitempty"`
+	// LastScaleTime is the time the target replicas were last changed
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+	// LastScaleDirection is the direction of the last change of the target replicas, Up or Down
+	// +optional
+	LastScaleDirection string `json:"lastScaleDirection,omitempty"`
+	// DeferredUntil is the end of the cooldown deferring the desired replicas
+	// +optional
+	DeferredUntil *metav1.Time `json:"deferredUntil,omitempty"`
+}
+
+// RampStatus is the progress of a ramp of the target replicas
+type RampStatus struct {
+	From  int32 `json:"from"`
+	To    int32 `json:"to"`
+	Step  int32 `json:"step"`
+	Steps int32 `json:"steps"`
+
+	StartTime metav1.Time `json:"startTime"`
+	// NextStepTime is when the next step is written, it is unset once the ramp is complete
+	// +optional
+	NextStepTime *metav1.Time `json:"nextStepTime,omitempty"`
+}
+
+// ReplicaRecommendation is a clamped job output and the time it was produced
+type ReplicaRecommendation struct {
+	Time     metav1.Time `json:"time"`
+	Replicas int32       `json:"replicas"`
+}
+
+// Verification is a scale up of the target waiting for its pods to become ready
+type Verification struct {
+	// From is the replicas written before the scale up
+	From int32 `json:"from"`
+	To   int32 `json:"to"`
+	// RollbackTo is the replicas restored by a rollback, written before the ramp or the earlier scale ups
+	// still verified, From when unset
+	// +optional
+	RollbackTo *int32 `json:"rollbackTo,omitempty"`
+
+	StartTime metav1.Time `json:"startTime"`
+	Deadline  metav1.Time `json:"deadline"`
+}
+
+// PendingResult is a job result held until the scheduled time it was computed for
+type PendingResult struct {
+	// Replicas are the job output clamped to the min and max replicas
+	Replicas int32 `json:"replicas"`
+	// EffectiveTime is the scheduled time the result is applied at
+	EffectiveTime metav1.Time `json:"effectiveTime"`
+}
+
+// TimelinePoint is the replicas a job predicts from a time on
+type TimelinePoint struct {
+	At       metav1.Time `json:"at"`
+	Replicas int32       `json:"replicas"`
+}
+
+// TimelineStatus is a replica timeline printed by a job and the progress of its application
+type TimelineStatus struct {
+	// Points are the points of the timeline, unset when they are stored in the ConfigMap
+	// +optional
+	Points []TimelinePoint `json:"points,omitempty"`
+	// ConfigMapName is the ConfigMap holding the points of a timeline too large for the status
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// Length is the number of points of the timeline
+	Length int32 `json:"length"`
+	// Applied is the number of points applied or skipped so far
+	Applied int32 `json:"applied"`
+	// NextTime is the time of the next point, it is unset once the timeline is exhausted
+	// +optional
+	NextTime *metav1.Time `json:"nextTime,omitempty"`
+	// EndTime is the time of the last point
+	EndTime metav1.Time `json:"endTime"`
+}
+
+// PrewarmStatus is a scale up of the target whose capacity is provisioned by placeholder pods
+type PrewarmStatus struct {
+	// From is the replicas of the deployment behind the target before the scale up
+	From int32 `json:"from"`
+	To   int32 `json:"to"`
+	// Placeholders is the number of placeholder pods, one for each replica of the scale up not ready yet
+	Placeholders int32 `json:"placeholders"`
+	// ApplyTime is when the scale up is scheduled to be written
+	ApplyTime metav1.Time `json:"applyTime"`
+}
+
+// QuotaAllocation is the share of an EifaReplicaQuota allocated to an EifaReplica
+type QuotaAllocation struct {
+	// Quota is the name of the EifaReplicaQuota limiting the replicas
+	Quota     string `json:"quota"`
+	Desired   int32  `json:"desired"`
+	Allocated int32  `json:"allocated"`
+	// Message explains which EifaReplicas were served first
+	Message string `json:"message"`
+}
+
+// ApprovalProposal is a change of the target replicas waiting for an approval
+type ApprovalProposal struct {
+	// ID is the value of the annotation approving or rejecting the proposal
+	ID   string `json:"id"`
+	From int32  `json:"from"`
+	To   int32  `json:"to"`
+
+	ProposedTime metav1.Time `json:"proposedTime"`
+	// ExpiryTime is the next schedule slot, the proposal is dropped when it was not approved by then
+	ExpiryTime metav1.Time `json:"expiryTime"`
+}
+
+// ScaleEvent is a change of the target replicas, negative when scaling down
+type ScaleEvent struct {
+	Time          metav1.Time `json:"time"`
+	ReplicaChange int32       `json:"replicaChange"`
 }
 
 // +kubebuilder:object:root=true
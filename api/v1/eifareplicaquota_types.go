@@ -0,0 +1,108 @@
+/*
+Copyright 2025 Erfan Mahvash.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	QUOTA_CLAMP = "Clamp"
+	QUOTA_DEFER = "Defer"
+)
+
+const (
+	BUDGET_LIMITED = "BudgetLimited"
+)
+
+// EifaReplicaQuotaSpec defines the budget of the EifaReplicas of a namespace
+type EifaReplicaQuotaSpec struct {
+	// MaxReplicas caps the sum of the replicas driven by the EifaReplicas of the namespace
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// MaxCPU caps the sum of the cpu requested by the pods of the driven replicas,
+	// derived from the pod template of the targets
+	// +optional
+	MaxCPU *resource.Quantity `json:"maxCPU,omitempty"`
+
+	// MaxMemory caps the sum of the memory requested by the pods of the driven replicas,
+	// derived from the pod template of the targets
+	// +optional
+	MaxMemory *resource.Quantity `json:"maxMemory,omitempty"`
+
+	// Action taken on a scale up exceeding the budget: Clamp writes the replicas fitting the budget,
+	// Defer leaves the target untouched, the rest of the change is retried until it fits
+	// +kubebuilder:validation:Enum=Clamp;Defer
+	// +kubebuilder:default=Clamp
+	// +optional
+	Action string `json:"action,omitempty"`
+}
+
+// EifaReplicaQuotaStatus defines the observed usage of the budget
+type EifaReplicaQuotaStatus struct {
+	// UsedReplicas is the sum of the replicas driven by the EifaReplicas of the namespace
+	UsedReplicas int32 `json:"usedReplicas"`
+	// +optional
+	UsedCPU *resource.Quantity `json:"usedCPU,omitempty"`
+	// +optional
+	UsedMemory *resource.Quantity `json:"usedMemory,omitempty"`
+
+	// Constrained lists the EifaReplicas allowed less than their desired replicas by the budget
+	// +optional
+	Constrained []QuotaConstraint `json:"constrained,omitempty"`
+}
+
+// QuotaConstraint is a scale up of an EifaReplica limited by the budget
+type QuotaConstraint struct {
+	// Name of the EifaReplica
+	Name string `json:"name"`
+	// Desired is the replicas the EifaReplica wanted to write
+	Desired int32 `json:"desired"`
+	// Allowed is the replicas it was allowed to write
+	Allowed  int32       `json:"allowed"`
+	Priority int32       `json:"priority"`
+	Time     metav1.Time `json:"time"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=erq
+
+// EifaReplicaQuota is the Schema for the eifareplicaquotas API
+type EifaReplicaQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EifaReplicaQuotaSpec   `json:"spec,omitempty"`
+	Status EifaReplicaQuotaStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// EifaReplicaQuotaList contains a list of EifaReplicaQuota
+type EifaReplicaQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EifaReplicaQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EifaReplicaQuota{}, &EifaReplicaQuotaList{})
+}
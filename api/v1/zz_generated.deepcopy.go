@@ -25,6 +25,83 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnomalyDetection) DeepCopyInto(out *AnomalyDetection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnomalyDetection.
+func (in *AnomalyDetection) DeepCopy() *AnomalyDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(AnomalyDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Approval) DeepCopyInto(out *Approval) {
+	*out = *in
+	if in.MinReplicaChange != nil {
+		in, out := &in.MinReplicaChange, &out.MinReplicaChange
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinPercentChange != nil {
+		in, out := &in.MinPercentChange, &out.MinPercentChange
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Approval.
+func (in *Approval) DeepCopy() *Approval {
+	if in == nil {
+		return nil
+	}
+	out := new(Approval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalProposal) DeepCopyInto(out *ApprovalProposal) {
+	*out = *in
+	in.ProposedTime.DeepCopyInto(&out.ProposedTime)
+	in.ExpiryTime.DeepCopyInto(&out.ExpiryTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalProposal.
+func (in *ApprovalProposal) DeepCopy() *ApprovalProposal {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalProposal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreaker) DeepCopyInto(out *CircuitBreaker) {
+	*out = *in
+	if in.MaxOpenSeconds != nil {
+		in, out := &in.MaxOpenSeconds, &out.MaxOpenSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreaker.
+func (in *CircuitBreaker) DeepCopy() *CircuitBreaker {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreaker)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EifaReplica) DeepCopyInto(out *EifaReplica) {
 	*out = *in
@@ -52,6 +129,36 @@ func (in *EifaReplica) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EifaReplicaBehavior) DeepCopyInto(out *EifaReplicaBehavior) {
+	*out = *in
+	if in.ScaleUp != nil {
+		in, out := &in.ScaleUp, &out.ScaleUp
+		*out = new(ScalingRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScaleDown != nil {
+		in, out := &in.ScaleDown, &out.ScaleDown
+		*out = new(ScalingRules)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ramp != nil {
+		in, out := &in.Ramp, &out.Ramp
+		*out = new(RampConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EifaReplicaBehavior.
+func (in *EifaReplicaBehavior) DeepCopy() *EifaReplicaBehavior {
+	if in == nil {
+		return nil
+	}
+	out := new(EifaReplicaBehavior)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EifaReplicaList) DeepCopyInto(out *EifaReplicaList) {
 	*out = *in
@@ -84,11 +191,202 @@ func (in *EifaReplicaList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EifaReplicaQuota) DeepCopyInto(out *EifaReplicaQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EifaReplicaQuota.
+func (in *EifaReplicaQuota) DeepCopy() *EifaReplicaQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(EifaReplicaQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EifaReplicaQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EifaReplicaQuotaList) DeepCopyInto(out *EifaReplicaQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EifaReplicaQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EifaReplicaQuotaList.
+func (in *EifaReplicaQuotaList) DeepCopy() *EifaReplicaQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(EifaReplicaQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EifaReplicaQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EifaReplicaQuotaSpec) DeepCopyInto(out *EifaReplicaQuotaSpec) {
+	*out = *in
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxCPU != nil {
+		in, out := &in.MaxCPU, &out.MaxCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxMemory != nil {
+		in, out := &in.MaxMemory, &out.MaxMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EifaReplicaQuotaSpec.
+func (in *EifaReplicaQuotaSpec) DeepCopy() *EifaReplicaQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EifaReplicaQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EifaReplicaQuotaStatus) DeepCopyInto(out *EifaReplicaQuotaStatus) {
+	*out = *in
+	if in.UsedCPU != nil {
+		in, out := &in.UsedCPU, &out.UsedCPU
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.UsedMemory != nil {
+		in, out := &in.UsedMemory, &out.UsedMemory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Constrained != nil {
+		in, out := &in.Constrained, &out.Constrained
+		*out = make([]QuotaConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EifaReplicaQuotaStatus.
+func (in *EifaReplicaQuotaStatus) DeepCopy() *EifaReplicaQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EifaReplicaQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EifaReplicaSpec) DeepCopyInto(out *EifaReplicaSpec) {
 	*out = *in
 	out.ScaleTargetRef = in.ScaleTargetRef
 	in.JobTemplate.DeepCopyInto(&out.JobTemplate)
+	if in.LeadTime != nil {
+		in, out := &in.LeadTime, &out.LeadTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Precedence != nil {
+		in, out := &in.Precedence, &out.Precedence
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(EifaReplicaBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(Fallback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CircuitBreaker != nil {
+		in, out := &in.CircuitBreaker, &out.CircuitBreaker
+		*out = new(CircuitBreaker)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxResultAge != nil {
+		in, out := &in.MaxResultAge, &out.MaxResultAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.BaselineReplicas != nil {
+		in, out := &in.BaselineReplicas, &out.BaselineReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AnomalyDetection != nil {
+		in, out := &in.AnomalyDetection, &out.AnomalyDetection
+		*out = new(AnomalyDetection)
+		**out = **in
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(Approval)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxHourlyCost != nil {
+		in, out := &in.MaxHourlyCost, &out.MaxHourlyCost
+		*out = new(string)
+		**out = **in
+	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(Verify)
+		**out = **in
+	}
+	if in.Prewarm != nil {
+		in, out := &in.Prewarm, &out.Prewarm
+		*out = new(Prewarm)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EifaReplicaSpec.
@@ -111,6 +409,115 @@ func (in *EifaReplicaStatus) DeepCopyInto(out *EifaReplicaStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastJobOutput != nil {
+		in, out := &in.LastJobOutput, &out.LastJobOutput
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(Verification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Prewarm != nil {
+		in, out := &in.Prewarm, &out.Prewarm
+		*out = new(PrewarmStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingResult != nil {
+		in, out := &in.PendingResult, &out.PendingResult
+		*out = new(PendingResult)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeline != nil {
+		in, out := &in.Timeline, &out.Timeline
+		*out = new(TimelineStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Allocation != nil {
+		in, out := &in.Allocation, &out.Allocation
+		*out = new(QuotaAllocation)
+		**out = **in
+	}
+	if in.ShadowReplicas != nil {
+		in, out := &in.ShadowReplicas, &out.ShadowReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RecommendedReplicas != nil {
+		in, out := &in.RecommendedReplicas, &out.RecommendedReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DesiredReplicas != nil {
+		in, out := &in.DesiredReplicas, &out.DesiredReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LastSuccessfulRunTime != nil {
+		in, out := &in.LastSuccessfulRunTime, &out.LastSuccessfulRunTime
+		*out = (*in).DeepCopy()
+	}
+	if in.AcceptedOutputs != nil {
+		in, out := &in.AcceptedOutputs, &out.AcceptedOutputs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.RejectedOutput != nil {
+		in, out := &in.RejectedOutput, &out.RejectedOutput
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RejectedOutputs != nil {
+		in, out := &in.RejectedOutputs, &out.RejectedOutputs
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResultTTLSeconds != nil {
+		in, out := &in.ResultTTLSeconds, &out.ResultTTLSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SlotEndTime != nil {
+		in, out := &in.SlotEndTime, &out.SlotEndTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CircuitOpenUntil != nil {
+		in, out := &in.CircuitOpenUntil, &out.CircuitOpenUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = make([]ReplicaRecommendation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ScaleEvents != nil {
+		in, out := &in.ScaleEvents, &out.ScaleEvents
+		*out = make([]ScaleEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ramp != nil {
+		in, out := &in.Ramp, &out.Ramp
+		*out = new(RampStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingApproval != nil {
+		in, out := &in.PendingApproval, &out.PendingApproval
+		*out = new(ApprovalProposal)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DeferredUntil != nil {
+		in, out := &in.DeferredUntil, &out.DeferredUntil
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EifaReplicaStatus.
@@ -123,6 +530,191 @@ func (in *EifaReplicaStatus) DeepCopy() *EifaReplicaStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Fallback) DeepCopyInto(out *Fallback) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Fallback.
+func (in *Fallback) DeepCopy() *Fallback {
+	if in == nil {
+		return nil
+	}
+	out := new(Fallback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingResult) DeepCopyInto(out *PendingResult) {
+	*out = *in
+	in.EffectiveTime.DeepCopyInto(&out.EffectiveTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingResult.
+func (in *PendingResult) DeepCopy() *PendingResult {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Prewarm) DeepCopyInto(out *Prewarm) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Prewarm.
+func (in *Prewarm) DeepCopy() *Prewarm {
+	if in == nil {
+		return nil
+	}
+	out := new(Prewarm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrewarmStatus) DeepCopyInto(out *PrewarmStatus) {
+	*out = *in
+	in.ApplyTime.DeepCopyInto(&out.ApplyTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrewarmStatus.
+func (in *PrewarmStatus) DeepCopy() *PrewarmStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PrewarmStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaAllocation) DeepCopyInto(out *QuotaAllocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaAllocation.
+func (in *QuotaAllocation) DeepCopy() *QuotaAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaConstraint) DeepCopyInto(out *QuotaConstraint) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaConstraint.
+func (in *QuotaConstraint) DeepCopy() *QuotaConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RampConfig) DeepCopyInto(out *RampConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RampConfig.
+func (in *RampConfig) DeepCopy() *RampConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RampConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RampStatus) DeepCopyInto(out *RampStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.NextStepTime != nil {
+		in, out := &in.NextStepTime, &out.NextStepTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RampStatus.
+func (in *RampStatus) DeepCopy() *RampStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RampStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaRecommendation) DeepCopyInto(out *ReplicaRecommendation) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaRecommendation.
+func (in *ReplicaRecommendation) DeepCopy() *ReplicaRecommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaRecommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicy) DeepCopyInto(out *RetryPolicy) {
+	*out = *in
+	if in.MaxBackoffSeconds != nil {
+		in, out := &in.MaxBackoffSeconds, &out.MaxBackoffSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicy.
+func (in *RetryPolicy) DeepCopy() *RetryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaleEvent) DeepCopyInto(out *ScaleEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleEvent.
+func (in *ScaleEvent) DeepCopy() *ScaleEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaleEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScaleTargetRef) DeepCopyInto(out *ScaleTargetRef) {
 	*out = *in
@@ -137,3 +729,133 @@ func (in *ScaleTargetRef) DeepCopy() *ScaleTargetRef {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPolicy) DeepCopyInto(out *ScalingPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPolicy.
+func (in *ScalingPolicy) DeepCopy() *ScalingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingRules) DeepCopyInto(out *ScalingRules) {
+	*out = *in
+	if in.StabilizationWindowSeconds != nil {
+		in, out := &in.StabilizationWindowSeconds, &out.StabilizationWindowSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SelectPolicy != nil {
+		in, out := &in.SelectPolicy, &out.SelectPolicy
+		*out = new(string)
+		**out = **in
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]ScalingPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.CooldownSeconds != nil {
+		in, out := &in.CooldownSeconds, &out.CooldownSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingRules.
+func (in *ScalingRules) DeepCopy() *ScalingRules {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimelinePoint) DeepCopyInto(out *TimelinePoint) {
+	*out = *in
+	in.At.DeepCopyInto(&out.At)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimelinePoint.
+func (in *TimelinePoint) DeepCopy() *TimelinePoint {
+	if in == nil {
+		return nil
+	}
+	out := new(TimelinePoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TimelineStatus) DeepCopyInto(out *TimelineStatus) {
+	*out = *in
+	if in.Points != nil {
+		in, out := &in.Points, &out.Points
+		*out = make([]TimelinePoint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextTime != nil {
+		in, out := &in.NextTime, &out.NextTime
+		*out = (*in).DeepCopy()
+	}
+	in.EndTime.DeepCopyInto(&out.EndTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TimelineStatus.
+func (in *TimelineStatus) DeepCopy() *TimelineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TimelineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Verification) DeepCopyInto(out *Verification) {
+	*out = *in
+	if in.RollbackTo != nil {
+		in, out := &in.RollbackTo, &out.RollbackTo
+		*out = new(int32)
+		**out = **in
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.Deadline.DeepCopyInto(&out.Deadline)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Verification.
+func (in *Verification) DeepCopy() *Verification {
+	if in == nil {
+		return nil
+	}
+	out := new(Verification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Verify) DeepCopyInto(out *Verify) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Verify.
+func (in *Verify) DeepCopy() *Verify {
+	if in == nil {
+		return nil
+	}
+	out := new(Verify)
+	in.DeepCopyInto(out)
+	return out
+}
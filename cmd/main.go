@@ -19,16 +19,24 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -37,6 +45,8 @@ import (
 
 	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
 	"github.com/erfan-272758/eifa-replica-operator/internal/controller"
+	"github.com/erfan-272758/eifa-replica-operator/internal/externalmetrics"
+	webhookschedulev1 "github.com/erfan-272758/eifa-replica-operator/internal/webhook/v1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -58,6 +68,8 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableExternalMetrics bool
+	var priceConfigMap string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -69,6 +81,12 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableExternalMetrics, "enable-external-metrics", false,
+		"If set, the desired replicas of each EifaReplica are served through the external.metrics.k8s.io API "+
+			"on the webhook server, so HPAs can consume them.")
+	flag.StringVar(&priceConfigMap, "price-configmap", "",
+		"The namespace/name of the ConfigMap holding the hourly price of a cpu core, a GiB of memory "+
+			"or a unit of any other resource. If set, the cost of the targets is estimated and spec.maxHourlyCost enforced.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -92,8 +110,16 @@ func main() {
 		tlsOpts = append(tlsOpts, disableHTTP2)
 	}
 
+	webhookTLSOpts := tlsOpts
+	if enableExternalMetrics {
+		// the kube-aggregator authenticates with a client certificate, which is verified by the provider
+		webhookTLSOpts = append(slices.Clone(tlsOpts), func(c *tls.Config) {
+			c.ClientAuth = tls.RequestClientCert
+		})
+	}
+
 	webhookServer := webhook.NewServer(webhook.Options{
-		TLSOpts: tlsOpts,
+		TLSOpts: webhookTLSOpts,
 	})
 
 	// Metrics endpoint is enabled in 'config/default/kustomization.yaml'. The Metrics options configure the server.
@@ -120,8 +146,30 @@ func main() {
 		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
 	}
 
+	var priceConfigMapKey types.NamespacedName
+	if priceConfigMap != "" {
+		namespace, name, ok := strings.Cut(priceConfigMap, "/")
+		if !ok || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("expected namespace/name, got %q", priceConfigMap), "invalid --price-configmap")
+			os.Exit(1)
+		}
+		priceConfigMapKey = types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	// only the price ConfigMap is cached, the other ConfigMaps are read directly
+	var cacheOptions cache.Options
+	if priceConfigMapKey.Name != "" {
+		cacheOptions.ByObject = map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}: {
+				Namespaces: map[string]cache.Config{priceConfigMapKey.Namespace: {}},
+				Field:      fields.OneTermEqualSelector("metadata.name", priceConfigMapKey.Name),
+			},
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
@@ -145,12 +193,27 @@ func main() {
 	}
 
 	if err = (&controller.EifaReplicaReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		Recorder:       mgr.GetEventRecorderFor("eifareplica-controller"),
+		APIReader:      mgr.GetAPIReader(),
+		PriceConfigMap: priceConfigMapKey,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EifaReplica")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookschedulev1.SetupEifaReplicaWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "EifaReplica")
+			os.Exit(1)
+		}
+	}
+	if enableExternalMetrics {
+		provider := externalmetrics.NewProvider(mgr.GetClient(), mgr.GetAPIReader())
+		mgr.GetWebhookServer().Register(externalmetrics.APIPath, provider)
+		mgr.GetWebhookServer().Register(externalmetrics.APIPath+"/", provider)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
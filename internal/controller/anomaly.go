@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// rejectedOutputError is returned for a job output rejected as anomalous
+type rejectedOutputError struct {
+	output int32
+	median float64
+}
+
+func (e *rejectedOutputError) Error() string {
+	return fmt.Sprintf("job output %d deviates too much from the median %g of the accepted outputs", e.output, e.median)
+}
+
+// median returns the median of outputs
+func median(outputs []int32) float64 {
+	sorted := slices.Clone(outputs)
+	slices.Sort(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return float64(sorted[mid])
+	}
+	return float64(sorted[mid-1]+sorted[mid]) / 2
+}
+
+// defaultLevelShiftThreshold is the level shift threshold when .Spec.AnomalyDetection.LevelShiftThreshold is unset
+const defaultLevelShiftThreshold = 3
+
+// deviates reports whether value exceeds the median m by more than factor or falls below it
+func deviates(value, m, factor float64) bool {
+	return value > m*factor || value < m/factor
+}
+
+// levelShift reports whether the consecutive rejected outputs agree with each other, every one of them
+// is within factor of their median
+func levelShift(rejected []int32, factor float64) bool {
+	m := max(1, median(rejected))
+	for _, output := range rejected {
+		if deviates(float64(output), m, factor) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkOutput records output in the accepted outputs, or returns a rejectedOutputError when
+// it deviates from their median by more than spec.anomalyDetection.maxDeviationFactor. Consecutive
+// rejected outputs agreeing with each other are accepted as a shift of the level, they replace the
+// accepted outputs so the median follows the new level.
+func checkOutput(eifaReplica *schedulev1.EifaReplica, output int32) error {
+	detection := eifaReplica.Spec.AnomalyDetection
+	if detection == nil {
+		eifaReplica.Status.AcceptedOutputs = nil
+		eifaReplica.Status.RejectedOutput = nil
+		eifaReplica.Status.RejectedOutputs = nil
+		return nil
+	}
+
+	accepted := eifaReplica.Status.AcceptedOutputs
+	if len(accepted) >= int(detection.HistorySize) {
+		factor, err := strconv.ParseFloat(detection.MaxDeviationFactor, 64)
+		if err != nil {
+			return fmt.Errorf("can not parse .Spec.AnomalyDetection.MaxDeviationFactor, %s", err)
+		}
+		m := max(1, median(accepted[len(accepted)-int(detection.HistorySize):]))
+		if deviates(float64(output), m, factor) {
+			threshold := int(detection.LevelShiftThreshold)
+			if threshold <= 0 {
+				threshold = defaultLevelShiftThreshold
+			}
+			rejected := append(slices.Clone(eifaReplica.Status.RejectedOutputs), output)
+			if len(rejected) > threshold {
+				rejected = rejected[len(rejected)-threshold:]
+			}
+			if len(rejected) < threshold || !levelShift(rejected, factor) {
+				eifaReplica.Status.RejectedOutput = &output
+				eifaReplica.Status.RejectedOutputs = rejected
+				return &rejectedOutputError{output: output, median: m}
+			}
+			// the level shifted, the median is computed on the outputs of the new level
+			accepted = rejected[:len(rejected)-1]
+		}
+	}
+
+	// keep only the outputs the median is computed on
+	accepted = append(accepted, output)
+	if len(accepted) > int(detection.HistorySize) {
+		accepted = accepted[len(accepted)-int(detection.HistorySize):]
+	}
+	eifaReplica.Status.AcceptedOutputs = accepted
+	eifaReplica.Status.RejectedOutput = nil
+	eifaReplica.Status.RejectedOutputs = nil
+	return nil
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Anomaly detection", func() {
+	var er *schedulev1.EifaReplica
+
+	BeforeEach(func() {
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			AnomalyDetection: &schedulev1.AnomalyDetection{HistorySize: 3, MaxDeviationFactor: "2.5"},
+		}}
+	})
+
+	It("should compute the median", func() {
+		Expect(median([]int32{9, 1, 5})).To(Equal(5.0))
+		Expect(median([]int32{4, 1, 5, 8})).To(Equal(4.5))
+	})
+
+	It("should accept outputs until the history is full", func() {
+		for _, output := range []int32{40, 1, 42} {
+			Expect(checkOutput(er, output)).To(Succeed())
+		}
+		Expect(er.Status.AcceptedOutputs).To(Equal([]int32{40, 1, 42}))
+	})
+
+	It("should reject outputs deviating from the median", func() {
+		er.Status.AcceptedOutputs = []int32{38, 40, 44}
+
+		err := checkOutput(er, 1)
+		Expect(err).To(BeAssignableToTypeOf(&rejectedOutputError{}))
+		Expect(*er.Status.RejectedOutput).To(Equal(int32(1)))
+		Expect(checkOutput(er, 101)).NotTo(Succeed())
+		Expect(er.Status.AcceptedOutputs).To(Equal([]int32{38, 40, 44}))
+
+		Expect(checkOutput(er, 90)).To(Succeed())
+		Expect(er.Status.RejectedOutput).To(BeNil())
+		Expect(er.Status.AcceptedOutputs).To(Equal([]int32{40, 44, 90}))
+	})
+
+	It("should accept consecutive rejected outputs agreeing with each other as a level shift", func() {
+		er.Status.AcceptedOutputs = []int32{5, 5, 6}
+
+		Expect(checkOutput(er, 80)).NotTo(Succeed())
+		Expect(checkOutput(er, 1)).NotTo(Succeed())
+		Expect(checkOutput(er, 82)).NotTo(Succeed())
+		Expect(er.Status.RejectedOutputs).To(Equal([]int32{80, 1, 82}))
+
+		// the outputs rejected since the 1 agree with each other
+		Expect(checkOutput(er, 78)).NotTo(Succeed())
+		Expect(checkOutput(er, 81)).To(Succeed())
+		Expect(er.Status.AcceptedOutputs).To(Equal([]int32{82, 78, 81}))
+		Expect(er.Status.RejectedOutputs).To(BeNil())
+		Expect(er.Status.RejectedOutput).To(BeNil())
+
+		// the median follows the new level
+		Expect(checkOutput(er, 5)).NotTo(Succeed())
+		Expect(checkOutput(er, 85)).To(Succeed())
+	})
+})
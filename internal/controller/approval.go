@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"strconv"
+	"time"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	approvalApproved = "Approved"
+	approvalRejected = "Rejected"
+	approvalExpired  = "Expired"
+)
+
+// requiresApproval reports whether the change from current to desired reaches a threshold of the approval,
+// any change from zero replicas reaches the percent threshold
+func requiresApproval(eifaReplica *schedulev1.EifaReplica, current, desired int32) bool {
+	approval := eifaReplica.Spec.Approval
+	if approval == nil || desired == current ||
+		eifaReplica.Spec.Mode == schedulev1.MODE_METRIC || eifaReplica.Spec.Mode == schedulev1.MODE_SHADOW {
+		return false
+	}
+	change := desired - current
+	if change < 0 {
+		change = -change
+	}
+	if approval.MinReplicaChange != nil && change >= *approval.MinReplicaChange {
+		return true
+	}
+	if approval.MinPercentChange != nil && int64(change)*100 >= int64(*approval.MinPercentChange)*int64(current) {
+		return true
+	}
+	return false
+}
+
+// propose records the change from current to desired as waiting for an approval until expiry,
+// it replaces the ramp or the deferred change in progress
+func propose(eifaReplica *schedulev1.EifaReplica, current, desired int32, now, expiry time.Time) *schedulev1.ApprovalProposal {
+	proposal := &schedulev1.ApprovalProposal{
+		ID:           strconv.FormatInt(now.Unix(), 10),
+		From:         current,
+		To:           desired,
+		ProposedTime: metav1.NewTime(now),
+		ExpiryTime:   metav1.NewTime(expiry),
+	}
+	eifaReplica.Status.PendingApproval = proposal
+	eifaReplica.Status.Ramp = nil
+	eifaReplica.Status.DeferredUntil = nil
+	return proposal
+}
+
+// approvalDecision returns the decision made on the pending proposal, empty while it is still pending,
+// an annotation only applies to the proposal whose id it holds
+func approvalDecision(eifaReplica *schedulev1.EifaReplica, now time.Time) string {
+	proposal := eifaReplica.Status.PendingApproval
+	if proposal == nil {
+		return ""
+	}
+	annotations := eifaReplica.GetAnnotations()
+	switch {
+	case annotations[schedulev1.REJECT_ANNOTATION] == proposal.ID:
+		return approvalRejected
+	case annotations[schedulev1.APPROVE_ANNOTATION] == proposal.ID:
+		return approvalApproved
+	case !now.Before(proposal.ExpiryTime.Time):
+		return approvalExpired
+	}
+	return ""
+}
+
+// approvalDue reports whether the pending proposal was approved, rejected or expired
+func approvalDue(eifaReplica *schedulev1.EifaReplica, now time.Time) bool {
+	return approvalDecision(eifaReplica, now) != ""
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Approval", func() {
+	var er *schedulev1.EifaReplica
+	now := time.Now()
+
+	BeforeEach(func() {
+		minChange, minPercent := int32(20), int32(50)
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			MinReplicas: 1,
+			MaxReplicas: 100,
+			Approval:    &schedulev1.Approval{MinReplicaChange: &minChange, MinPercentChange: &minPercent},
+		}}
+	})
+
+	It("should let small changes flow", func() {
+		Expect(requiresApproval(er, 40, 50)).To(BeFalse())
+		Expect(requiresApproval(er, 40, 60)).To(BeTrue())
+		Expect(requiresApproval(er, 10, 15)).To(BeTrue())
+		Expect(requiresApproval(er, 10, 4)).To(BeTrue())
+		Expect(requiresApproval(er, 0, 1)).To(BeTrue())
+
+		er.Spec.Mode = schedulev1.MODE_SHADOW
+		Expect(requiresApproval(er, 10, 90)).To(BeFalse())
+	})
+
+	It("should only apply the annotations holding the proposal id", func() {
+		er.Status.Ramp = &schedulev1.RampStatus{From: 5, To: 10}
+		proposal := propose(er, 10, 80, now, now.Add(time.Hour))
+		Expect(er.Status.PendingApproval).To(Equal(proposal))
+		Expect(er.Status.Ramp).To(BeNil())
+		Expect(approvalDue(er, now)).To(BeFalse())
+
+		er.Annotations = map[string]string{schedulev1.APPROVE_ANNOTATION: "1"}
+		Expect(approvalDecision(er, now)).To(BeEmpty())
+
+		er.Annotations[schedulev1.APPROVE_ANNOTATION] = proposal.ID
+		Expect(approvalDecision(er, now)).To(Equal(approvalApproved))
+
+		er.Annotations[schedulev1.REJECT_ANNOTATION] = proposal.ID
+		Expect(approvalDecision(er, now)).To(Equal(approvalRejected))
+	})
+
+	It("should expire the proposal at the next slot", func() {
+		propose(er, 10, 80, now, now.Add(time.Hour))
+		Expect(approvalDecision(er, now.Add(59*time.Minute))).To(BeEmpty())
+		Expect(approvalDecision(er, now.Add(time.Hour))).To(Equal(approvalExpired))
+		Expect(pendingRequeue(er, 2*time.Hour)).To(BeNumerically("<=", time.Hour))
+
+		er.Status.PendingApproval = nil
+		Expect(approvalDue(er, now.Add(time.Hour))).To(BeFalse())
+	})
+})
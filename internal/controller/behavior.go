@@ -0,0 +1,175 @@
+package controller
+
+import (
+	"math"
+	"time"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// decideReplicas returns the replicas to apply from current for the clamped job output recommendation,
+// the recommendation is stabilized then limited by the scaling policies and both values are recorded in the status
+func decideReplicas(eifaReplica *schedulev1.EifaReplica, current, recommendation int32, now time.Time) int32 {
+	stabilized := stabilize(eifaReplica, current, recommendation, now)
+	eifaReplica.Status.RecommendedReplicas = &stabilized
+
+	desired := limitScaling(eifaReplica, current, stabilized, now)
+	eifaReplica.Status.DesiredReplicas = &desired
+	return desired
+}
+
+// stabilizationWindow returns the stabilization window of rules, zero when it is not set
+func stabilizationWindow(rules *schedulev1.ScalingRules) time.Duration {
+	if rules == nil || rules.StabilizationWindowSeconds == nil {
+		return 0
+	}
+	return time.Duration(*rules.StabilizationWindowSeconds) * time.Second
+}
+
+// stabilize records recommendation in the status and returns the replicas to apply from current,
+// the same way the HPA does: scaling up is limited to the lowest recommendation within the scale up
+// window and scaling down to the highest recommendation within the scale down window.
+func stabilize(eifaReplica *schedulev1.EifaReplica, current, recommendation int32, now time.Time) int32 {
+	var upWindow, downWindow time.Duration
+	if behavior := eifaReplica.Spec.Behavior; behavior != nil {
+		upWindow = stabilizationWindow(behavior.ScaleUp)
+		downWindow = stabilizationWindow(behavior.ScaleDown)
+	}
+
+	upRecommendation, downRecommendation := recommendation, recommendation
+	upCutoff, downCutoff := now.Add(-upWindow), now.Add(-downWindow)
+	for _, rec := range eifaReplica.Status.Recommendations {
+		if rec.Time.Time.After(upCutoff) {
+			upRecommendation = min(upRecommendation, rec.Replicas)
+		}
+		if rec.Time.Time.After(downCutoff) {
+			downRecommendation = max(downRecommendation, rec.Replicas)
+		}
+	}
+
+	// keep the recommendations needed by the largest window only
+	cutoff := now.Add(-max(upWindow, downWindow))
+	recommendations := []schedulev1.ReplicaRecommendation{}
+	for _, rec := range eifaReplica.Status.Recommendations {
+		if rec.Time.Time.After(cutoff) {
+			recommendations = append(recommendations, rec)
+		}
+	}
+	if upWindow > 0 || downWindow > 0 {
+		recommendations = append(recommendations, schedulev1.ReplicaRecommendation{Time: metav1.NewTime(now), Replicas: recommendation})
+	}
+	eifaReplica.Status.Recommendations = recommendations
+
+	desired := current
+	if desired < upRecommendation {
+		desired = upRecommendation
+	}
+	if desired > downRecommendation {
+		desired = downRecommendation
+	}
+	return desired
+}
+
+// scalingRules returns the scaling rules of the direction from current to desired
+func scalingRules(eifaReplica *schedulev1.EifaReplica, current, desired int32) *schedulev1.ScalingRules {
+	if eifaReplica.Spec.Behavior == nil {
+		return nil
+	}
+	if desired > current {
+		return eifaReplica.Spec.Behavior.ScaleUp
+	}
+	return eifaReplica.Spec.Behavior.ScaleDown
+}
+
+// limitScaling applies the scaling policies of the direction to the change from current to desired,
+// each policy is evaluated against the replicas at the start of its period like the HPA does.
+func limitScaling(eifaReplica *schedulev1.EifaReplica, current, desired int32, now time.Time) int32 {
+	rules := scalingRules(eifaReplica, current, desired)
+	if desired == current || rules == nil {
+		return desired
+	}
+	selectPolicy := schedulev1.SELECT_MAX
+	if rules.SelectPolicy != nil {
+		selectPolicy = *rules.SelectPolicy
+	}
+	if selectPolicy == schedulev1.SELECT_DISABLED {
+		return current
+	}
+	if len(rules.Policies) == 0 {
+		return desired
+	}
+
+	scaleUp := desired > current
+	var limit int32
+	for i, policy := range rules.Policies {
+		// replicas at the start of the period, before the changes made within it
+		periodStart := current
+		cutoff := now.Add(-time.Duration(policy.PeriodSeconds) * time.Second)
+		for _, event := range eifaReplica.Status.ScaleEvents {
+			if event.Time.Time.After(cutoff) {
+				periodStart -= event.ReplicaChange
+			}
+		}
+
+		var proposed int32
+		switch {
+		case scaleUp && policy.Type == schedulev1.POLICY_PODS:
+			proposed = periodStart + policy.Value
+		case scaleUp:
+			// a percentage of no replicas is none, the target grows from zero by at least one pod
+			proposed = max(periodStart+1, int32(math.Ceil(float64(periodStart)*(1+float64(policy.Value)/100))))
+		case policy.Type == schedulev1.POLICY_PODS:
+			proposed = periodStart - policy.Value
+		default:
+			proposed = int32(float64(periodStart) * (1 - float64(policy.Value)/100))
+		}
+
+		// Max selects the policy allowing the highest change, which is the lowest limit when scaling down
+		if i == 0 || (proposed > limit) == (scaleUp == (selectPolicy == schedulev1.SELECT_MAX)) {
+			limit = proposed
+		}
+	}
+
+	if scaleUp {
+		return max(current, min(desired, limit))
+	}
+	return min(current, max(desired, limit))
+}
+
+// recordScaleEvent records a change of the target replicas as the last scale action, only the events
+// within the longest policy period are kept
+func recordScaleEvent(eifaReplica *schedulev1.EifaReplica, change int32, now time.Time) {
+	var longest int32
+	if behavior := eifaReplica.Spec.Behavior; behavior != nil {
+		for _, rules := range []*schedulev1.ScalingRules{behavior.ScaleUp, behavior.ScaleDown} {
+			if rules == nil {
+				continue
+			}
+			for _, policy := range rules.Policies {
+				longest = max(longest, policy.PeriodSeconds)
+			}
+		}
+	}
+
+	cutoff := now.Add(-time.Duration(longest) * time.Second)
+	events := []schedulev1.ScaleEvent{}
+	for _, event := range eifaReplica.Status.ScaleEvents {
+		if event.Time.Time.After(cutoff) {
+			events = append(events, event)
+		}
+	}
+	if longest > 0 && change != 0 {
+		events = append(events, schedulev1.ScaleEvent{Time: metav1.NewTime(now), ReplicaChange: change})
+	}
+	eifaReplica.Status.ScaleEvents = events
+
+	if change != 0 {
+		lastScaleTime := metav1.NewTime(now)
+		eifaReplica.Status.LastScaleTime = &lastScaleTime
+		eifaReplica.Status.LastScaleDirection = schedulev1.DIRECTION_DOWN
+		if change > 0 {
+			eifaReplica.Status.LastScaleDirection = schedulev1.DIRECTION_UP
+		}
+	}
+}
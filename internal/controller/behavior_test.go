@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Behavior", func() {
+	now := time.Now()
+	window := func(s int32) *schedulev1.ScalingRules {
+		return &schedulev1.ScalingRules{StabilizationWindowSeconds: &s}
+	}
+	recommendation := func(ago time.Duration, replicas int32) schedulev1.ReplicaRecommendation {
+		return schedulev1.ReplicaRecommendation{Time: metav1.NewTime(now.Add(-ago)), Replicas: replicas}
+	}
+
+	It("should apply the job result directly without behavior", func() {
+		er := &schedulev1.EifaReplica{}
+		Expect(stabilize(er, 5, 2, now)).To(Equal(int32(2)))
+		Expect(stabilize(er, 5, 8, now)).To(Equal(int32(8)))
+		Expect(er.Status.Recommendations).To(BeEmpty())
+	})
+
+	It("should scale down to the highest recommendation of the window", func() {
+		er := &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			Behavior: &schedulev1.EifaReplicaBehavior{ScaleDown: window(300)},
+		}}
+		er.Status.Recommendations = []schedulev1.ReplicaRecommendation{
+			recommendation(10*time.Minute, 9),
+			recommendation(4*time.Minute, 6),
+			recommendation(time.Minute, 4),
+		}
+		Expect(stabilize(er, 8, 2, now)).To(Equal(int32(6)))
+		// the expired recommendation is dropped and the new one is recorded
+		Expect(er.Status.Recommendations).To(HaveLen(3))
+		Expect(er.Status.Recommendations[2].Replicas).To(Equal(int32(2)))
+
+		// scaling up is not delayed
+		Expect(stabilize(er, 6, 10, now)).To(Equal(int32(10)))
+	})
+
+	It("should scale up to the lowest recommendation of the window", func() {
+		er := &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			Behavior: &schedulev1.EifaReplicaBehavior{ScaleUp: window(120)},
+		}}
+		er.Status.Recommendations = []schedulev1.ReplicaRecommendation{recommendation(time.Minute, 3)}
+		Expect(stabilize(er, 2, 10, now)).To(Equal(int32(3)))
+		// never scale down because of a lower past recommendation
+		Expect(stabilize(er, 5, 10, now)).To(Equal(int32(5)))
+	})
+
+	It("should limit the change per period with the scaling policies", func() {
+		policies := &schedulev1.ScalingRules{Policies: []schedulev1.ScalingPolicy{
+			{Type: schedulev1.POLICY_PODS, Value: 4, PeriodSeconds: 60},
+			{Type: schedulev1.POLICY_PERCENT, Value: 50, PeriodSeconds: 60},
+		}}
+		er := &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			Behavior: &schedulev1.EifaReplicaBehavior{ScaleDown: policies},
+		}}
+
+		// a job printing 0 can only remove half of the replicas
+		Expect(decideReplicas(er, 20, 0, now)).To(Equal(int32(10)))
+		Expect(*er.Status.RecommendedReplicas).To(Equal(int32(0)))
+		Expect(*er.Status.DesiredReplicas).To(Equal(int32(10)))
+
+		// changes made within the period count against the limit
+		recordScaleEvent(er, -10, now)
+		Expect(decideReplicas(er, 10, 0, now.Add(30*time.Second))).To(Equal(int32(10)))
+		Expect(decideReplicas(er, 10, 0, now.Add(90*time.Second))).To(Equal(int32(5)))
+
+		// Min selects the most restrictive policy
+		selectMin := schedulev1.SELECT_MIN
+		policies.SelectPolicy = &selectMin
+		Expect(decideReplicas(er, 20, 0, now.Add(time.Hour))).To(Equal(int32(16)))
+
+		// scaling up is not limited
+		Expect(decideReplicas(er, 20, 40, now.Add(time.Hour))).To(Equal(int32(40)))
+	})
+
+	It("should scale up from zero with a percent policy", func() {
+		er := &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			Behavior: &schedulev1.EifaReplicaBehavior{ScaleUp: &schedulev1.ScalingRules{Policies: []schedulev1.ScalingPolicy{
+				{Type: schedulev1.POLICY_PERCENT, Value: 100, PeriodSeconds: 60},
+			}}},
+		}}
+		Expect(limitScaling(er, 0, 10, now)).To(Equal(int32(1)))
+		Expect(limitScaling(er, 1, 10, now)).To(Equal(int32(2)))
+		Expect(limitScaling(er, 4, 10, now)).To(Equal(int32(8)))
+	})
+
+	It("should not scale when the direction is disabled", func() {
+		disabled := schedulev1.SELECT_DISABLED
+		er := &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			Behavior: &schedulev1.EifaReplicaBehavior{ScaleUp: &schedulev1.ScalingRules{SelectPolicy: &disabled}},
+		}}
+		Expect(decideReplicas(er, 3, 10, now)).To(Equal(int32(3)))
+		Expect(decideReplicas(er, 3, 1, now)).To(Equal(int32(1)))
+	})
+})
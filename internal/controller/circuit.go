@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// open interval of the circuit breaker when spec.circuitBreaker.openSeconds is not persisted
+const defaultCircuitOpen = 10 * time.Minute
+
+// openCircuit opens the circuit breaker once the failed schedule slots reach its threshold,
+// it returns when the circuit closes again for a probe run
+func (r *EifaReplicaReconciler) openCircuit(eifaReplica *schedulev1.EifaReplica, now time.Time) (time.Time, bool) {
+	breaker := eifaReplica.Spec.CircuitBreaker
+	if breaker == nil || eifaReplica.Status.ConsecutiveFailures < breaker.FailureThreshold {
+		return time.Time{}, false
+	}
+
+	open := defaultCircuitOpen
+	if breaker.OpenSeconds > 0 {
+		open = time.Duration(breaker.OpenSeconds) * time.Second
+	}
+	eifaReplica.Status.CircuitTrips++
+	until := now.Add(exponentialBackoff(open, breaker.MaxOpenSeconds, eifaReplica.Status.CircuitTrips))
+	openUntil := metav1.NewTime(until)
+	eifaReplica.Status.CircuitOpenUntil = &openUntil
+
+	msg := fmt.Sprintf("%d consecutive failed schedule slots, no job is run until %s",
+		eifaReplica.Status.ConsecutiveFailures, until.Format(time.RFC3339))
+	r.Recorder.Event(eifaReplica, corev1.EventTypeWarning, "CircuitOpen", msg)
+	appendCondition(eifaReplica, metav1.Condition{
+		Type:               schedulev1.CIRCUIT_OPEN,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             "ConsecutiveFailures",
+		Message:            msg,
+	})
+	return until, true
+}
+
+// closeCircuit closes the circuit breaker after a successful probe run
+func (r *EifaReplicaReconciler) closeCircuit(eifaReplica *schedulev1.EifaReplica, now time.Time) {
+	if eifaReplica.Status.CircuitOpenUntil == nil {
+		return
+	}
+	eifaReplica.Status.CircuitOpenUntil = nil
+	eifaReplica.Status.CircuitTrips = 0
+
+	msg := "the probe job succeeded"
+	r.Recorder.Event(eifaReplica, corev1.EventTypeNormal, "CircuitClosed", msg)
+	appendCondition(eifaReplica, metav1.Condition{
+		Type:               schedulev1.CIRCUIT_OPEN,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: metav1.NewTime(now),
+		Reason:             "ProbeSucceeded",
+		Message:            msg,
+	})
+}
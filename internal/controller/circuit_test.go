@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Circuit breaker", func() {
+	var (
+		er         *schedulev1.EifaReplica
+		recorder   *record.FakeRecorder
+		reconciler *EifaReplicaReconciler
+	)
+	now := time.Now()
+
+	BeforeEach(func() {
+		maxOpen := int32(1800)
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			CircuitBreaker: &schedulev1.CircuitBreaker{FailureThreshold: 3, OpenSeconds: 600, MaxOpenSeconds: &maxOpen},
+		}}
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &EifaReplicaReconciler{Recorder: recorder}
+	})
+
+	It("should open after the failure threshold with an increasing interval", func() {
+		er.Status.ConsecutiveFailures = 2
+		_, open := reconciler.openCircuit(er, now)
+		Expect(open).To(BeFalse())
+
+		intervals := []time.Duration{}
+		for er.Status.ConsecutiveFailures = 3; er.Status.ConsecutiveFailures < 7; er.Status.ConsecutiveFailures++ {
+			until, open := reconciler.openCircuit(er, now)
+			Expect(open).To(BeTrue())
+			intervals = append(intervals, until.Sub(now))
+		}
+		Expect(intervals).To(Equal([]time.Duration{10 * time.Minute, 20 * time.Minute, 30 * time.Minute, 30 * time.Minute}))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Warning CircuitOpen")))
+		Expect(er.Status.Conditions[0].Type).To(Equal(schedulev1.CIRCUIT_OPEN))
+	})
+
+	It("should close on a successful probe", func() {
+		reconciler.closeCircuit(er, now)
+		Expect(er.Status.Conditions).To(BeEmpty())
+
+		er.Status.CircuitTrips = 2
+		er.Status.CircuitOpenUntil = &metav1.Time{Time: now}
+		reconciler.closeCircuit(er, now)
+		Expect(er.Status.CircuitOpenUntil).To(BeNil())
+		Expect(er.Status.CircuitTrips).To(BeZero())
+		Expect(er.Status.Conditions[0].Status).To(Equal(metav1.ConditionFalse))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Normal CircuitClosed")))
+	})
+})
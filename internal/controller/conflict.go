@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// scaleTargetIndex indexes EifaReplicas and HorizontalPodAutoscalers by the object they scale
+const scaleTargetIndex = ".spec.scaleTargetRef"
+
+// targetKey returns the normalized index key of a scale target, e.g. deployment/nginx
+func targetKey(kind, name string) string {
+	return fmt.Sprintf("%s/%s", normalizeKind(kind), name)
+}
+
+func indexEifaReplicaTarget(obj client.Object) []string {
+	eifaReplica := obj.(*schedulev1.EifaReplica)
+	if eifaReplica.Spec.ScaleTargetRef.Name == "" {
+		return nil
+	}
+	return []string{targetKey(eifaReplica.Spec.ScaleTargetRef.Kind, eifaReplica.Spec.ScaleTargetRef.Name)}
+}
+
+func indexHPATarget(obj client.Object) []string {
+	hpa := obj.(*autoscalingv2.HorizontalPodAutoscaler)
+	return []string{targetKey(hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name)}
+}
+
+// conflicts describes the other controllers which scale the same target as an EifaReplica
+type conflicts struct {
+	eifaReplicas []schedulev1.EifaReplica
+	hpas         []autoscalingv2.HorizontalPodAutoscaler
+}
+
+func (c *conflicts) empty() bool {
+	return len(c.eifaReplicas) == 0 && len(c.hpas) == 0
+}
+
+func (c *conflicts) String() string {
+	var names []string
+	for _, er := range c.eifaReplicas {
+		names = append(names, fmt.Sprintf("EifaReplica/%s", er.Name))
+	}
+	for _, hpa := range c.hpas {
+		names = append(names, fmt.Sprintf("HorizontalPodAutoscaler/%s", hpa.Name))
+	}
+	return strings.Join(names, ", ")
+}
+
+// wins reports whether the precedence of eifaReplica allows it to scale the target despite the conflicts
+func (c *conflicts) wins(eifaReplica *schedulev1.EifaReplica) bool {
+	if eifaReplica.Spec.Precedence == nil {
+		return false
+	}
+	for _, er := range c.eifaReplicas {
+		if er.Spec.Precedence != nil && *er.Spec.Precedence >= *eifaReplica.Spec.Precedence {
+			return false
+		}
+	}
+	return true
+}
+
+// findConflicts lists the EifaReplicas and HPAs in the namespace which scale the same target as eifaReplica,
+// and the EifaReplicas scaling the workload behind target through another object, such as one writing
+// the deployment whose HPA eifaReplica drives. The other objects are found through the scale target index:
+// the deployment behind target, the HPAs of that deployment and the ScaledObjects controlling them, so a
+// sibling whose target can not be resolved is not a conflict.
+func (r *EifaReplicaReconciler) findConflicts(ctx context.Context, req ctrl.Request, eifaReplica *schedulev1.EifaReplica, target scaleTarget) (*conflicts, error) {
+	key := targetKey(eifaReplica.Spec.ScaleTargetRef.Kind, eifaReplica.Spec.ScaleTargetRef.Name)
+	workloadKey := targetKey(workloadRef(target))
+	result := &conflicts{}
+
+	keys := []string{key}
+	if workloadKey != key {
+		keys = append(keys, workloadKey)
+	}
+	// the HPAs of the workload, directly or created by KEDA for a ScaledObject
+	workloadHPAs := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := r.List(ctx, workloadHPAs, client.InNamespace(req.Namespace), client.MatchingFields{scaleTargetIndex: workloadKey}); err != nil {
+		return nil, fmt.Errorf("can not list HorizontalPodAutoscalers, %s", err)
+	}
+	for _, hpa := range workloadHPAs.Items {
+		keys = append(keys, targetKey(kindHorizontalPodAutoscaler, hpa.Name))
+		if owner := metav1.GetControllerOf(&hpa); owner != nil && normalizeKind(owner.Kind) == kindScaledObject {
+			keys = append(keys, targetKey(kindScaledObject, owner.Name))
+		}
+	}
+
+	seen := map[types.UID]bool{eifaReplica.UID: true}
+	for _, k := range keys {
+		eifaReplicaList := &schedulev1.EifaReplicaList{}
+		if err := r.List(ctx, eifaReplicaList, client.InNamespace(req.Namespace), client.MatchingFields{scaleTargetIndex: k}); err != nil {
+			return nil, fmt.Errorf("can not list EifaReplicas, %s", err)
+		}
+		for _, er := range eifaReplicaList.Items {
+			if !seen[er.UID] {
+				seen[er.UID] = true
+				result.eifaReplicas = append(result.eifaReplicas, er)
+			}
+		}
+	}
+
+	hpaList := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := r.List(ctx, hpaList, client.InNamespace(req.Namespace), client.MatchingFields{scaleTargetIndex: key}); err != nil {
+		return nil, fmt.Errorf("can not list HorizontalPodAutoscalers, %s", err)
+	}
+	result.hpas = hpaList.Items
+
+	return result, nil
+}
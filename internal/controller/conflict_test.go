@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Conflicts", func() {
+	precedence := func(p int32) *int32 { return &p }
+	eifaReplica := func(name string, p *int32) schedulev1.EifaReplica {
+		return schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       schedulev1.EifaReplicaSpec{Precedence: p},
+		}
+	}
+
+	It("should normalize target kinds", func() {
+		Expect(targetKey("deploy", "nginx")).To(Equal("deployment/nginx"))
+		Expect(targetKey("Deployment", "nginx")).To(Equal("deployment/nginx"))
+	})
+
+	It("should refuse to scale without precedence", func() {
+		self := eifaReplica("self", nil)
+		c := &conflicts{hpas: []autoscalingv2.HorizontalPodAutoscaler{{ObjectMeta: metav1.ObjectMeta{Name: "hpa"}}}}
+		Expect(c.wins(&self)).To(BeFalse())
+		Expect(c.String()).To(Equal("HorizontalPodAutoscaler/hpa"))
+	})
+
+	It("should let the highest precedence win", func() {
+		self := eifaReplica("self", precedence(10))
+		c := &conflicts{eifaReplicas: []schedulev1.EifaReplica{eifaReplica("low", precedence(1)), eifaReplica("unset", nil)}}
+		Expect(c.wins(&self)).To(BeTrue())
+
+		c.eifaReplicas = append(c.eifaReplicas, eifaReplica("tie", precedence(10)))
+		Expect(c.wins(&self)).To(BeFalse())
+	})
+
+	It("should detect EifaReplicas scaling the workload behind an autoscaler", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(schedulev1.AddToScheme(scheme)).To(Succeed())
+
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+			},
+		}
+		// the HPA KEDA creates for a ScaledObject, whose target is not resolved
+		isController := true
+		kedaHPA := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "keda-hpa-queue", Namespace: "default", OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "keda.sh/v1alpha1", Kind: "ScaledObject", Name: "queue", UID: "queue-uid", Controller: &isController,
+			}}},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+			},
+		}
+		queue := &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "queue", Namespace: "default", UID: "queue-er-uid"},
+			Spec:       schedulev1.EifaReplicaSpec{ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "ScaledObject", Name: "queue"}},
+		}
+		bounds := &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "bounds", Namespace: "default", UID: "bounds-uid"},
+			Spec:       schedulev1.EifaReplicaSpec{ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "HorizontalPodAutoscaler", Name: "web"}},
+		}
+		direct := &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "direct", Namespace: "default", UID: "direct-uid"},
+			Spec:       schedulev1.EifaReplicaSpec{ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "Deployment", Name: "web"}},
+		}
+		other := &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default", UID: "other-uid"},
+			Spec:       schedulev1.EifaReplicaSpec{ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "Deployment", Name: "api"}},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment, hpa, kedaHPA, queue, bounds, direct, other).
+			WithIndex(&autoscalingv2.HorizontalPodAutoscaler{}, scaleTargetIndex, indexHPATarget).
+			WithIndex(&schedulev1.EifaReplica{}, scaleTargetIndex, indexEifaReplicaTarget).Build()
+		reconciler := &EifaReplicaReconciler{Client: c, Scheme: scheme}
+
+		target, err := reconciler.getScaleTarget(ctx, bounds)
+		Expect(err).NotTo(HaveOccurred())
+		found, err := reconciler.findConflicts(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(bounds)}, bounds, target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.String()).To(Equal("EifaReplica/direct, EifaReplica/queue"))
+
+		target, err = reconciler.getScaleTarget(ctx, direct)
+		Expect(err).NotTo(HaveOccurred())
+		found, err = reconciler.findConflicts(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(direct)}, direct, target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.String()).To(Equal("EifaReplica/queue, EifaReplica/bounds, HorizontalPodAutoscaler/keda-hpa-queue, HorizontalPodAutoscaler/web"))
+	})
+})
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"time"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// cooldownUntil returns the end of the cooldown following the last scale action,
+// the zero time when the direction of the last action has no cooldown
+func cooldownUntil(eifaReplica *schedulev1.EifaReplica) time.Time {
+	if eifaReplica.Status.LastScaleTime == nil || eifaReplica.Spec.Behavior == nil {
+		return time.Time{}
+	}
+	rules := eifaReplica.Spec.Behavior.ScaleDown
+	if eifaReplica.Status.LastScaleDirection == schedulev1.DIRECTION_UP {
+		rules = eifaReplica.Spec.Behavior.ScaleUp
+	}
+	if rules == nil || rules.CooldownSeconds == nil {
+		return time.Time{}
+	}
+	return eifaReplica.Status.LastScaleTime.Add(time.Duration(*rules.CooldownSeconds) * time.Second)
+}
+
+// scheduleChange returns the replicas to write for a change from current to desired: the change is deferred
+// while the cooldown of the last scale action lasts, otherwise it is written at once or through a ramp
+func scheduleChange(eifaReplica *schedulev1.EifaReplica, current, desired int32, now time.Time) int32 {
+	eifaReplica.Status.DeferredUntil = nil
+	if desired != current {
+		if until := cooldownUntil(eifaReplica); now.Before(until) {
+			deferredUntil := metav1.NewTime(until)
+			eifaReplica.Status.DeferredUntil = &deferredUntil
+			eifaReplica.Status.Ramp = nil
+			return current
+		}
+	}
+	return planRamp(eifaReplica, current, desired, now)
+}
+
+// deferredDue reports whether the cooldown deferring the desired replicas is over
+func deferredDue(eifaReplica *schedulev1.EifaReplica, now time.Time) bool {
+	deferredUntil := eifaReplica.Status.DeferredUntil
+	return deferredUntil != nil && eifaReplica.Status.DesiredReplicas != nil && !now.Before(deferredUntil.Time)
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Cooldown", func() {
+	var er *schedulev1.EifaReplica
+	now := time.Now()
+	cooldown := func(s int32) *schedulev1.ScalingRules {
+		return &schedulev1.ScalingRules{CooldownSeconds: &s}
+	}
+
+	BeforeEach(func() {
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			Behavior: &schedulev1.EifaReplicaBehavior{ScaleUp: cooldown(60), ScaleDown: cooldown(600)},
+		}}
+	})
+
+	It("should record the last scale action", func() {
+		recordScaleEvent(er, 3, now)
+		Expect(er.Status.LastScaleDirection).To(Equal(schedulev1.DIRECTION_UP))
+		Expect(er.Status.LastScaleTime.Time).To(BeTemporally("==", now))
+		Expect(cooldownUntil(er)).To(BeTemporally("==", now.Add(time.Minute)))
+
+		recordScaleEvent(er, -1, now)
+		Expect(er.Status.LastScaleDirection).To(Equal(schedulev1.DIRECTION_DOWN))
+		Expect(cooldownUntil(er)).To(BeTemporally("==", now.Add(10*time.Minute)))
+	})
+
+	It("should defer scale actions during the cooldown", func() {
+		recordScaleEvent(er, -2, now)
+		desired := int32(8)
+		er.Status.DesiredReplicas = &desired
+
+		// a reversal is deferred as well
+		Expect(scheduleChange(er, 4, desired, now.Add(time.Minute))).To(Equal(int32(4)))
+		Expect(er.Status.DeferredUntil.Time).To(BeTemporally("==", now.Add(10*time.Minute)))
+		Expect(deferredDue(er, now.Add(5*time.Minute))).To(BeFalse())
+		Expect(deferredDue(er, now.Add(10*time.Minute))).To(BeTrue())
+
+		Expect(scheduleChange(er, 4, desired, now.Add(10*time.Minute))).To(Equal(desired))
+		Expect(er.Status.DeferredUntil).To(BeNil())
+	})
+
+	It("should not defer without a change", func() {
+		recordScaleEvent(er, 1, now)
+		Expect(scheduleChange(er, 4, 4, now)).To(Equal(int32(4)))
+		Expect(er.Status.DeferredUntil).To(BeNil())
+	})
+})
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// priceTable is the hourly price of a unit of each resource, a cpu core, a GiB of memory or a GPU
+type priceTable map[corev1.ResourceName]float64
+
+// parsePriceTable parses the data of the price ConfigMap, each key is a resource name such as cpu,
+// memory or nvidia.com/gpu and each value its hourly price
+func parsePriceTable(data map[string]string) (priceTable, error) {
+	prices := priceTable{}
+	for name, value := range data {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return nil, fmt.Errorf("invalid price %q of %s", value, name)
+		}
+		prices[corev1.ResourceName(name)] = price
+	}
+	return prices, nil
+}
+
+// uncachedReader returns the reader of the ConfigMaps other than the price ConfigMap, they are read
+// directly so the ConfigMaps of the cluster are not cached
+func (r *EifaReplicaReconciler) uncachedReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+// priceTable reads the price ConfigMap from the cache, which only holds that ConfigMap, nil when the
+// operator was not given one
+func (r *EifaReplicaReconciler) priceTable(ctx context.Context) (priceTable, error) {
+	if r.PriceConfigMap.Name == "" {
+		return nil, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, r.PriceConfigMap, configMap); err != nil {
+		return nil, fmt.Errorf("can not get the price configmap %s, %s", r.PriceConfigMap, err)
+	}
+	return parsePriceTable(configMap.Data)
+}
+
+// replicaHourlyCost returns the hourly cost of a pod of template, the extended resources such as GPUs
+// are only requested through their limits
+func replicaHourlyCost(template *corev1.PodTemplateSpec, prices priceTable) float64 {
+	var cost float64
+	for _, container := range template.Spec.Containers {
+		requests := container.Resources.Requests.DeepCopy()
+		if requests == nil {
+			requests = corev1.ResourceList{}
+		}
+		for name, quantity := range container.Resources.Limits {
+			if _, ok := requests[name]; !ok && name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+				requests[name] = quantity
+			}
+		}
+		for name, quantity := range requests {
+			price, ok := prices[name]
+			if !ok {
+				continue
+			}
+			switch name {
+			case corev1.ResourceCPU:
+				cost += price * float64(quantity.MilliValue()) / 1000
+			case corev1.ResourceMemory:
+				cost += price * float64(quantity.Value()) / (1 << 30)
+			default:
+				cost += price * quantity.AsApproximateFloat64()
+			}
+		}
+	}
+	return cost
+}
+
+// targetHourlyCost returns the hourly cost of a replica of target, nil when there is no price table
+// or the workload behind target has no known pod template
+func (r *EifaReplicaReconciler) targetHourlyCost(ctx context.Context, eifaReplica *schedulev1.EifaReplica, target scaleTarget) (*float64, error) {
+	prices, err := r.priceTable(ctx)
+	if err != nil || prices == nil {
+		return nil, err
+	}
+	template, err := r.podTemplate(ctx, eifaReplica.Namespace, target)
+	if err != nil {
+		return nil, fmt.Errorf("can not get the pod template of the target, %s", err)
+	}
+	if template == nil {
+		return nil, nil
+	}
+	cost := replicaHourlyCost(template, prices)
+	return &cost, nil
+}
+
+// costCap returns the most replicas costing perReplica each within the max hourly cost, never below the
+// min replicas, false when there is no ceiling
+func costCap(eifaReplica *schedulev1.EifaReplica, perReplica float64) (int32, bool) {
+	if eifaReplica.Spec.MaxHourlyCost == nil || perReplica <= 0 {
+		return 0, false
+	}
+	maxCost, err := strconv.ParseFloat(*eifaReplica.Spec.MaxHourlyCost, 64)
+	if err != nil {
+		// rejected by the admission webhook
+		return 0, false
+	}
+	return max(eifaReplica.Spec.MinReplicas, int32(min(math.Floor(maxCost/perReplica), math.MaxInt32))), true
+}
+
+// recordCost records the hourly cost of replicas costing perReplica each, the cost is forgotten when
+// it can not be estimated
+func recordCost(eifaReplica *schedulev1.EifaReplica, replicas int32, perReplica *float64) {
+	if perReplica == nil {
+		eifaReplica.Status.HourlyCost = ""
+		forgetCost(client.ObjectKeyFromObject(eifaReplica))
+		return
+	}
+	cost := float64(replicas) * *perReplica
+	eifaReplica.Status.HourlyCost = strconv.FormatFloat(cost, 'f', 2, 64)
+	observeCost(eifaReplica, cost)
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Cost", func() {
+	var er *schedulev1.EifaReplica
+	var template *corev1.PodTemplateSpec
+	var prices priceTable
+
+	BeforeEach(func() {
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{MinReplicas: 2, MaxReplicas: 100}}
+		er.Namespace, er.Name = "team", "inference"
+		template = &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("2Gi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:                    resource.MustParse("2"),
+					corev1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+				},
+			}},
+			{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("500m"),
+			}}},
+		}}}
+		var err error
+		prices, err = parsePriceTable(map[string]string{"cpu": "0.04", "memory": "0.005", "nvidia.com/gpu": "1.2"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject invalid prices", func() {
+		_, err := parsePriceTable(map[string]string{"cpu": "cheap"})
+		Expect(err).To(HaveOccurred())
+		_, err = parsePriceTable(map[string]string{"cpu": "-1"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should price the requests of a replica", func() {
+		// 1 core, 2GiB and a GPU requested through its limit
+		Expect(replicaHourlyCost(template, prices)).To(BeNumerically("~", 0.04+0.01+1.2, 1e-9))
+
+		delete(prices, "nvidia.com/gpu")
+		Expect(replicaHourlyCost(template, prices)).To(BeNumerically("~", 0.05, 1e-9))
+	})
+
+	It("should cap the replicas under the max hourly cost", func() {
+		_, ok := costCap(er, 1.25)
+		Expect(ok).To(BeFalse())
+
+		maxCost := "20"
+		er.Spec.MaxHourlyCost = &maxCost
+		ceiling, ok := costCap(er, 1.25)
+		Expect(ok).To(BeTrue())
+		Expect(ceiling).To(Equal(int32(16)))
+
+		maxCost = "1"
+		ceiling, _ = costCap(er, 1.25)
+		Expect(ceiling).To(Equal(int32(2)))
+	})
+
+	It("should record the hourly cost", func() {
+		perReplica := 1.25
+		recordCost(er, 4, &perReplica)
+		Expect(er.Status.HourlyCost).To(Equal("5.00"))
+		Expect(testutil.ToFloat64(hourlyCostGauge.WithLabelValues("team", "inference", ""))).To(Equal(5.0))
+
+		recordCost(er, 4, nil)
+		Expect(er.Status.HourlyCost).To(BeEmpty())
+		Expect(hourlyCostGauge.DeleteLabelValues("team", "inference", "")).To(BeFalse())
+	})
+})
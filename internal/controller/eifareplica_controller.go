@@ -18,18 +18,24 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -39,16 +45,29 @@ import (
 // EifaReplicaReconciler reconciles a EifaReplica object
 type EifaReplicaReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// APIReader reads the objects which are not worth caching, such as the price ConfigMap
+	APIReader client.Reader
+	// PriceConfigMap is the ConfigMap holding the price table of the cost estimations, empty when disabled
+	PriceConfigMap types.NamespacedName
 }
 
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups="",resources=pods/log,verbs=get;
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=keda.sh,resources=scaledobjects,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=schedule.eifa.org,resources=eifareplicas,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=schedule.eifa.org,resources=eifareplicas/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=schedule.eifa.org,resources=eifareplicas/finalizers,verbs=update
+// +kubebuilder:rbac:groups=schedule.eifa.org,resources=eifareplicaquotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=schedule.eifa.org,resources=eifareplicaquotas/status,verbs=get;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -67,6 +86,7 @@ func (r *EifaReplicaReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if err := r.Get(ctx, req.NamespacedName, eifaReplica); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("EifaReplica resource not found. Ignoring since object must be deleted")
+			forgetReplicas(req.NamespacedName)
 
 			return ctrl.Result{}, nil
 		}
@@ -80,32 +100,140 @@ func (r *EifaReplicaReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	requeueAfter := 15 * time.Second
 
 	// Calculate desired replicas based on JobTemplate and Scheduler
+	pending, timeline := eifaReplica.Status.PendingResult, eifaReplica.Status.Timeline
 	desiredReplicas, next, err := r.GetDesiredReplica(ctx, req, eifaReplica)
 	if next != nil {
 		requeueAfter = time.Until(*next)
 	}
+	newTimeline := eifaReplica.Status.Timeline != nil && eifaReplica.Status.Timeline != timeline
+	if newTimeline {
+		appendCondition(eifaReplica, metav1.Condition{
+			Type:               schedulev1.TIMELINE,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "TimelineStored",
+			Message:            describeTimeline(eifaReplica.Status.Timeline),
+		})
+	}
 	if err != nil {
+		msg := fmt.Sprintf("[get-desired-replica] %s", err)
+		if fallbackActive(eifaReplica) {
+			// the job keeps failing, apply the fallback instead of its output
+			desiredReplicas = fallbackReplicas(eifaReplica)
+			if desiredReplicas != nil {
+				msg += fmt.Sprintf(", falling back to %d replicas after %d consecutive failures",
+					*desiredReplicas, eifaReplica.Status.ConsecutiveFailures)
+			} else {
+				msg += fmt.Sprintf(", keeping the current replicas after %d consecutive failures",
+					eifaReplica.Status.ConsecutiveFailures)
+				eifaReplica.Status.Ramp = nil
+				eifaReplica.Status.DeferredUntil = nil
+			}
+		}
+
 		// update status
-		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+		cond := &metav1.Condition{
 			Type:               schedulev1.FAILED,
 			Status:             metav1.ConditionTrue,
 			LastTransitionTime: metav1.Now(),
 			Reason:             "GetDesiredReplicaError",
-			Message:            fmt.Sprintf("[get-desired-replica] %s", err),
+			Message:            msg,
+		}
+		var rejected *rejectedOutputError
+		if errors.As(err, &rejected) {
+			cond.Type = schedulev1.REJECTED_OUTPUT
+			cond.Reason = "AnomalousOutput"
+		}
+		r.UpdateStatus(ctx, eifaReplica, cond, next)
+
+		if desiredReplicas == nil {
+			return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+		}
+	}
+
+	if held := eifaReplica.Status.PendingResult; held != nil && held != pending {
+		// the job ran ahead of its scheduled time, the result is applied then
+		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.RESULT_PENDING,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "LeadTime",
+			Message: fmt.Sprintf("job result of %d replicas is applied at %s",
+				held.Replicas, held.EffectiveTime.Format(time.RFC3339)),
 		}, next)
+		return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+	}
+	if newTimeline && desiredReplicas == nil {
+		// no point of the timeline is due yet
+		r.UpdateStatus(ctx, eifaReplica, nil, next)
+		return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+	}
 
-		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	now := time.Now()
+	if eifaReplica.Status.Verification != nil {
+		if eifaReplica.Spec.Mode == schedulev1.MODE_METRIC || eifaReplica.Spec.Mode == schedulev1.MODE_SHADOW {
+			// the target is no longer written
+			eifaReplica.Status.Verification = nil
+		} else if rolledBack, err := r.verifyTarget(ctx, eifaReplica, now, next); err != nil {
+			r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+				Type:               schedulev1.FAILED,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "VerifyTargetError",
+				Message:            fmt.Sprintf("[verify-target] %s", err),
+			}, next)
+		} else if rolledBack {
+			return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+		}
+	}
+	// provision the capacity of an upcoming scale up with placeholder pods
+	if err := r.prewarm(ctx, eifaReplica, now, next); err != nil {
+		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.FAILED,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "PrewarmError",
+			Message:            fmt.Sprintf("[prewarm] %s", err),
+		}, next)
 	}
 
-	if desiredReplicas == nil {
+	// the EifaReplicaQuotas record no share of an EifaReplica in Shadow mode, there is no reallocation to pick up
+	reallocate := eifaReplica.Spec.Mode != schedulev1.MODE_METRIC && eifaReplica.Spec.Mode != schedulev1.MODE_SHADOW &&
+		r.allocationDue(ctx, eifaReplica)
+	if desiredReplicas == nil && !staleDue(eifaReplica, now) && !rampDue(eifaReplica, now) && !deferredDue(eifaReplica, now) &&
+		!approvalDue(eifaReplica, now) && !reallocate {
 		// dose not need to change anythings
-		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+	}
+
+	if eifaReplica.Spec.Mode == schedulev1.MODE_METRIC {
+		// desired replicas are only published through the external metrics API,
+		// so the scaling behavior is applied against the last published value
+		var current, desired int32
+		if eifaReplica.Status.DesiredReplicas != nil {
+			current = *eifaReplica.Status.DesiredReplicas
+		} else if desiredReplicas != nil {
+			current = *desiredReplicas
+		}
+		if desiredReplicas != nil {
+			desired = decideReplicas(eifaReplica, current, *desiredReplicas, now)
+		} else if staleDue(eifaReplica, now) {
+			desired = revertToBaseline(eifaReplica)
+		} else {
+			return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+		}
+		recordScaleEvent(eifaReplica, desired-current, now)
+		observeReplicas(eifaReplica, desired, nil)
+		eifaReplica.Status.Ramp = nil
+		eifaReplica.Status.DeferredUntil = nil
+		r.UpdateStatus(ctx, eifaReplica, nil, next)
+		return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
 	}
 
 	// Fetch target
-	kind := strings.ToLower(eifaReplica.Spec.ScaleTargetRef.Kind)
-	if kind != "deployment" || kind == "deploy" {
-		err = fmt.Errorf(".Spec.ScaleTargetRef.Kind must be deploy of deployment got %s", kind)
+	kind := normalizeKind(eifaReplica.Spec.ScaleTargetRef.Kind)
+	if kind != kindDeployment && kind != kindHorizontalPodAutoscaler && kind != kindScaledObject {
+		err = fmt.Errorf(".Spec.ScaleTargetRef.Kind must be a deployment, a horizontalpodautoscaler or a scaledobject got %s", kind)
 		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
 			Type:               schedulev1.FAILED,
 			Status:             metav1.ConditionTrue,
@@ -115,9 +243,8 @@ func (r *EifaReplicaReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}, next)
 		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
-	targetObj := &appsv1.Deployment{}
-
-	if err := r.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: eifaReplica.Spec.ScaleTargetRef.Name}, targetObj); err != nil {
+	target, err := r.getScaleTarget(ctx, eifaReplica)
+	if err != nil {
 		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
 			Type:               schedulev1.FAILED,
 			Status:             metav1.ConditionTrue,
@@ -129,11 +256,241 @@ func (r *EifaReplicaReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{RequeueAfter: requeueAfter}, client.IgnoreNotFound(err)
 	}
 
+	// Apply the scaling behavior to the job result, a new result re-plans the ramp in progress
+	// and the deferred desired replicas are written once the cooldown is over, large changes
+	// wait for an approval until the next schedule slot
+	current := target.Replicas()
+	var desired int32
+	switch {
+	case desiredReplicas != nil:
+		eifaReplica.Status.PendingApproval = nil
+		decided := decideReplicas(eifaReplica, current, *desiredReplicas, now)
+		if requiresApproval(eifaReplica, current, decided) {
+			expiry := now.Add(requeueAfter)
+			if next != nil {
+				expiry = *next
+			}
+			proposal := propose(eifaReplica, current, decided, now, expiry)
+			msg := fmt.Sprintf("change of target replica from %d to %d waits for approval, annotate with %s=%s or %s=%s",
+				current, decided, schedulev1.APPROVE_ANNOTATION, proposal.ID, schedulev1.REJECT_ANNOTATION, proposal.ID)
+			r.Recorder.Event(eifaReplica, corev1.EventTypeNormal, "ApprovalRequired", msg)
+			r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+				Type:               schedulev1.PENDING_APPROVAL,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "ApprovalRequired",
+				Message:            msg,
+			}, next)
+			return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+		}
+		desired = scheduleChange(eifaReplica, current, decided, now)
+	case staleDue(eifaReplica, now):
+		eifaReplica.Status.PendingApproval = nil
+		desired = revertToBaseline(eifaReplica)
+	case approvalDue(eifaReplica, now):
+		proposal := eifaReplica.Status.PendingApproval
+		decision := approvalDecision(eifaReplica, now)
+		eifaReplica.Status.PendingApproval = nil
+		if decision != approvalApproved {
+			r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+				Type:               schedulev1.PENDING_APPROVAL,
+				Status:             metav1.ConditionFalse,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "Approval" + decision,
+				Message: fmt.Sprintf("proposal %s changing target replica from %d to %d %s",
+					proposal.ID, proposal.From, proposal.To, strings.ToLower(decision)),
+			}, next)
+			return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+		}
+		desired = scheduleChange(eifaReplica, current, proposal.To, now)
+	case deferredDue(eifaReplica, now):
+		desired = scheduleChange(eifaReplica, current, *eifaReplica.Status.DesiredReplicas, now)
+	case reallocate && !rampDue(eifaReplica, now):
+		desired = reallocation(eifaReplica, current, now)
+	default:
+		desired = rampStep(eifaReplica)
+	}
+
+	// Estimate the cost of the target and keep it under the ceiling
+	perReplicaCost, err := r.targetHourlyCost(ctx, eifaReplica, target)
+	if err != nil {
+		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.FAILED,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "EstimateCostError",
+			Message:            fmt.Sprintf("[estimate-cost] %s", err),
+		}, next)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if perReplicaCost != nil {
+		if ceiling, ok := costCap(eifaReplica, *perReplicaCost); ok && ceiling < desired {
+			if desiredReplicas != nil {
+				appendCondition(eifaReplica, metav1.Condition{
+					Type:               schedulev1.COST_LIMITED,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.Now(),
+					Reason:             "MaxHourlyCost",
+					Message: fmt.Sprintf("target replica limited to %d instead of %d, a replica costs %.2f per hour and maxHourlyCost is %s",
+						ceiling, desired, *perReplicaCost, *eifaReplica.Spec.MaxHourlyCost),
+				})
+			}
+			desired = ceiling
+		}
+	}
+
+	// Shadow mode runs the budgets and quotas below but leaves the target untouched, the conflicts and
+	// the rollout only delay a write and are ignored
+	shadow := eifaReplica.Spec.Mode == schedulev1.MODE_SHADOW
+
+	// Refuse to fight with other controllers of the same target
+	conflicts, err := r.findConflicts(ctx, req, eifaReplica, target)
+	if err != nil && !shadow {
+		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.FAILED,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "FindConflictsError",
+			Message:            fmt.Sprintf("[find-conflicts] %s", err),
+		}, next)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if !shadow && !conflicts.empty() {
+		if !conflicts.wins(eifaReplica) {
+			r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+				Type:               schedulev1.CONFLICT,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "ConflictingControllers",
+				Message:            fmt.Sprintf("target is also scaled by %s, set .Spec.Precedence to take over", conflicts),
+			}, next)
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.CONFLICT,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "PrecedenceOverride",
+			Message:            fmt.Sprintf("target is also scaled by %s, overriding by precedence", conflicts),
+		}, next)
+	}
+
+	// Do not change the replicas in the middle of a rollout, the change is retried once it completes
+	if desired != current && !shadow {
+		deployment, err := r.waitingForRollout(ctx, eifaReplica, target)
+		if err != nil {
+			r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+				Type:               schedulev1.FAILED,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "WaitForRolloutError",
+				Message:            fmt.Sprintf("[wait-for-rollout] %s", err),
+			}, next)
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		if deployment != nil {
+			deferredUntil := metav1.NewTime(now.Add(rolloutRetryInterval))
+			eifaReplica.Status.DeferredUntil = &deferredUntil
+			eifaReplica.Status.Ramp = nil
+			var cond *metav1.Condition
+			if conditions := eifaReplica.Status.Conditions; desiredReplicas != nil ||
+				len(conditions) == 0 || conditions[len(conditions)-1].Type != schedulev1.WAITING_ROLLOUT {
+				// report the wait once, not every retry
+				cond = &metav1.Condition{
+					Type:               schedulev1.WAITING_ROLLOUT,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.Now(),
+					Reason:             "RolloutInProgress",
+					Message: fmt.Sprintf("change of target replica from %d to %d waits for the rollout of deployment %s, %d of %d replicas updated",
+						current, desired, deployment.Name, deployment.Status.UpdatedReplicas, deployment.Status.Replicas),
+				}
+			}
+			r.UpdateStatus(ctx, eifaReplica, cond, next)
+			return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+		}
+	}
+
+	// Keep the scale ups within the budgets of the namespace, the rest is retried later
+	allowed, quota, err := r.applyQuotas(ctx, eifaReplica, target, current, desired)
+	if err != nil {
+		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.FAILED,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "ApplyQuotasError",
+			Message:            fmt.Sprintf("[apply-quotas] %s", err),
+		}, next)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if quota != nil {
+		if desiredReplicas != nil || allowed != current {
+			// report a new job result or a progress only, not every retry
+			msg := fmt.Sprintf("target replica limited to %d instead of %d, %s", allowed, desired, eifaReplica.Status.Allocation.Message)
+			r.Recorder.Event(eifaReplica, corev1.EventTypeWarning, "BudgetLimited", msg)
+			appendCondition(eifaReplica, metav1.Condition{
+				Type:               schedulev1.BUDGET_LIMITED,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "EifaReplicaQuota",
+				Message:            msg,
+			})
+		}
+		deferredUntil := metav1.NewTime(now.Add(quotaRetryInterval))
+		eifaReplica.Status.DeferredUntil = &deferredUntil
+		eifaReplica.Status.Ramp = nil
+		desired = allowed
+	}
+
+	// Do not create pods the ResourceQuotas of the namespace would forbid
+	fitting, shortfall, err := r.applyResourceQuotas(ctx, eifaReplica, target, current, desired)
+	if err != nil {
+		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.FAILED,
+			Status:             metav1.ConditionTrue,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "ApplyResourceQuotasError",
+			Message:            fmt.Sprintf("[apply-resource-quotas] %s", err),
+		}, next)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	if shortfall != "" {
+		if desiredReplicas != nil || fitting != current {
+			msg := fmt.Sprintf("target replica limited to %d instead of %d, %s", fitting, desired, shortfall)
+			r.Recorder.Event(eifaReplica, corev1.EventTypeWarning, "QuotaLimited", msg)
+			appendCondition(eifaReplica, metav1.Condition{
+				Type:               schedulev1.QUOTA_LIMITED,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "ResourceQuota",
+				Message:            msg,
+			})
+		}
+		deferredUntil := metav1.NewTime(now.Add(quotaRetryInterval))
+		eifaReplica.Status.DeferredUntil = &deferredUntil
+		eifaReplica.Status.Ramp = nil
+		desired = fitting
+	}
+
+	if shadow {
+		// record the replicas Active mode would have written
+		if rampDue(eifaReplica, now) {
+			advanceRamp(eifaReplica)
+		}
+		shadowReplicas := target.SetReplicas(desired)
+		eifaReplica.Status.ShadowReplicas = &shadowReplicas
+		observeReplicas(eifaReplica, shadowReplicas, &current)
+		recordCost(eifaReplica, shadowReplicas, perReplicaCost)
+		if shadowReplicas != current {
+			r.Recorder.Eventf(eifaReplica, corev1.EventTypeNormal, "ShadowUpdate",
+				"would update target replica from %d to %d", current, shadowReplicas)
+		}
+		r.UpdateStatus(ctx, eifaReplica, nil, next)
+		return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
+	}
+
 	// Check current replicas against desired replicas
-	if *targetObj.Spec.Replicas != *desiredReplicas {
-		msg := fmt.Sprintf("update target replica from %d to %d", *targetObj.Spec.Replicas, *desiredReplicas)
-		targetObj.Spec.Replicas = desiredReplicas
-		if err := r.Update(ctx, targetObj); err != nil {
+	if applied := target.SetReplicas(desired); applied != current {
+		if err := r.Update(ctx, target.Object()); err != nil {
 			r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
 				Type:               schedulev1.FAILED,
 				Status:             metav1.ConditionTrue,
@@ -143,6 +500,29 @@ func (r *EifaReplicaReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			}, next)
 			return ctrl.Result{RequeueAfter: requeueAfter}, nil
 		}
+		msg := fmt.Sprintf("update target replica from %d to %d", current, applied)
+		if rampDue(eifaReplica, now) {
+			// the step is written, the next one is due at its time
+			advanceRamp(eifaReplica)
+		}
+		if ramp := eifaReplica.Status.Ramp; ramp != nil {
+			msg += fmt.Sprintf(", ramp step %d/%d to %d", ramp.Step, ramp.Steps, ramp.To)
+		} else if desiredReplicas == nil && eifaReplica.Status.Stale {
+			msg += ", reverted to the baseline as the last job result is stale"
+		}
+		recordScaleEvent(eifaReplica, applied-current, now)
+		observeReplicas(eifaReplica, applied, &applied)
+		recordCost(eifaReplica, applied, perReplicaCost)
+		if startVerification(eifaReplica, current, applied, now) {
+			appendCondition(eifaReplica, metav1.Condition{
+				Type:               schedulev1.PROGRESSING,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "WaitingForReadiness",
+				Message: fmt.Sprintf("waiting for %d ready replicas until %s",
+					applied, eifaReplica.Status.Verification.Deadline.Format(time.RFC3339)),
+			})
+		}
 		r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
 			Type:               schedulev1.SUCCESS,
 			Status:             metav1.ConditionTrue,
@@ -150,16 +530,35 @@ func (r *EifaReplicaReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			Reason:             "UpdateTargetReplica",
 			Message:            msg,
 		}, next)
+	} else {
+		if rampDue(eifaReplica, now) {
+			// the target already has the replicas of the step
+			advanceRamp(eifaReplica)
+		}
+		observeReplicas(eifaReplica, applied, &current)
+		recordCost(eifaReplica, applied, perReplicaCost)
+		// persist the job result, the scaling state and the next transition time
+		r.UpdateStatus(ctx, eifaReplica, nil, next)
 	}
 
-	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	return ctrl.Result{RequeueAfter: pendingRequeue(eifaReplica, requeueAfter)}, nil
 
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *EifaReplicaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	ctx := context.Background()
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &schedulev1.EifaReplica{}, scaleTargetIndex, indexEifaReplicaTarget); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &autoscalingv2.HorizontalPodAutoscaler{}, scaleTargetIndex, indexHPATarget); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&schedulev1.EifaReplica{}).
+		Watches(&schedulev1.EifaReplicaQuota{}, handler.EnqueueRequestsFromMapFunc(r.eifaReplicasOfQuota)).
+		Watches(&appsv1.Deployment{}, handler.EnqueueRequestsFromMapFunc(r.eifaReplicasOfDeployment)).
 		WithOptions(controller.TypedOptions[reconcile.Request]{MaxConcurrentReconciles: 100}).
 		Complete(r)
 }
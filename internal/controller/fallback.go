@@ -0,0 +1,27 @@
+package controller
+
+import (
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// fallbackActive reports whether the job failed often enough in a row for the fallback to apply
+func fallbackActive(eifaReplica *schedulev1.EifaReplica) bool {
+	fallback := eifaReplica.Spec.Fallback
+	return fallback != nil && eifaReplica.Status.ConsecutiveFailures >= fallback.AfterConsecutiveFailures
+}
+
+// fallbackReplicas returns the replicas applied instead of the job output by the fallback,
+// nil when the current replicas are kept
+func fallbackReplicas(eifaReplica *schedulev1.EifaReplica) *int32 {
+	fallback := eifaReplica.Spec.Fallback
+	var replicas int32
+	switch {
+	case fallback.ScaleToMax:
+		replicas = eifaReplica.Spec.MaxReplicas
+	case fallback.Replicas != nil:
+		replicas = max(eifaReplica.Spec.MinReplicas, min(eifaReplica.Spec.MaxReplicas, *fallback.Replicas))
+	default:
+		return nil
+	}
+	return &replicas
+}
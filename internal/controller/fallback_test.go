@@ -0,0 +1,39 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Fallback", func() {
+	var er *schedulev1.EifaReplica
+
+	BeforeEach(func() {
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			MinReplicas: 2,
+			MaxReplicas: 10,
+			Fallback:    &schedulev1.Fallback{AfterConsecutiveFailures: 3, ScaleToMax: true},
+		}}
+	})
+
+	It("should apply after consecutive failures only", func() {
+		er.Status.ConsecutiveFailures = 2
+		Expect(fallbackActive(er)).To(BeFalse())
+		er.Status.ConsecutiveFailures = 3
+		Expect(fallbackActive(er)).To(BeTrue())
+		Expect(*fallbackReplicas(er)).To(Equal(int32(10)))
+	})
+
+	It("should clamp the fallback replicas", func() {
+		replicas := int32(50)
+		er.Spec.Fallback = &schedulev1.Fallback{AfterConsecutiveFailures: 1, Replicas: &replicas}
+		Expect(*fallbackReplicas(er)).To(Equal(int32(10)))
+	})
+
+	It("should keep the current replicas", func() {
+		er.Spec.Fallback = &schedulev1.Fallback{AfterConsecutiveFailures: 1, KeepCurrent: true}
+		Expect(fallbackReplicas(er)).To(BeNil())
+	})
+})
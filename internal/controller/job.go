@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
@@ -18,23 +19,26 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (r *EifaReplicaReconciler) runJob(ctx context.Context, req ctrl.Request, eifaReplica *schedulev1.EifaReplica) (int32, error) {
+// jobResult is the result printed on the last line of the job logs,
+// either a replica count, a JSON object or a JSON array of timeline points
+type jobResult struct {
+	Replicas   int32  `json:"replicas"`
+	TTLSeconds *int32 `json:"ttlSeconds,omitempty"`
+
+	Timeline []schedulev1.TimelinePoint `json:"-"`
+}
+
+func (r *EifaReplicaReconciler) runJob(ctx context.Context, req ctrl.Request, eifaReplica *schedulev1.EifaReplica) (*jobResult, error) {
 	// 1. init job obj
 
-	// set defaults
-	defActiveSec := int64(15)
-	defBackoffLim := int32(1)
+	// deadline, backoff and restart policy defaults are persisted by the defaulting webhook,
+	// EifaReplicas it has not defaulted yet get them here
+	schedulev1.DefaultJobSpec(&eifaReplica.Spec.JobTemplate.Spec)
+	// a failed pod fails the job, so the failure reaches the retries and the fallback
+	eifaReplica.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
 	completions := int32(1)
 	parallelism := int32(1)
 
-	if eifaReplica.Spec.JobTemplate.Spec.ActiveDeadlineSeconds == nil {
-		eifaReplica.Spec.JobTemplate.Spec.ActiveDeadlineSeconds = &defActiveSec
-	}
-	if eifaReplica.Spec.JobTemplate.Spec.BackoffLimit == nil {
-		eifaReplica.Spec.JobTemplate.Spec.BackoffLimit = &defBackoffLim
-	}
-
-	eifaReplica.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
 	eifaReplica.Spec.JobTemplate.Spec.Completions = &completions
 	eifaReplica.Spec.JobTemplate.Spec.Parallelism = &parallelism
 
@@ -47,12 +51,12 @@ func (r *EifaReplicaReconciler) runJob(ctx context.Context, req ctrl.Request, ei
 	}
 	// 2. set owner ref
 	if err := ctrl.SetControllerReference(eifaReplica, job, r.Scheme); err != nil {
-		return 0, fmt.Errorf("can not set owner ref, %s", err)
+		return nil, fmt.Errorf("can not set owner ref, %s", err)
 	}
 
 	// 3. create job
 	if err := r.Client.Create(ctx, job); err != nil {
-		return 0, fmt.Errorf("can not create job, %s", err)
+		return nil, fmt.Errorf("can not create job, %s", err)
 	}
 
 	// 4. wait for completion
@@ -63,7 +67,7 @@ func (r *EifaReplicaReconciler) runJob(ctx context.Context, req ctrl.Request, ei
 	for {
 		err := r.Get(ctx, jobKey, &compJob)
 		if err != nil {
-			return 0, fmt.Errorf("can not get created job, %s", err)
+			return nil, fmt.Errorf("can not get created job, %s", err)
 		}
 		jobStatus := r.checkJobStatus(&compJob)
 
@@ -74,27 +78,27 @@ func (r *EifaReplicaReconciler) runJob(ctx context.Context, req ctrl.Request, ei
 
 		// job ends without any success pods
 		if jobStatus == schedulev1.JOB_FAILED {
-			return 0, fmt.Errorf("job ends without any success pods")
+			return nil, fmt.Errorf("job ends without any success pods")
 		}
 
 		time.Sleep(interval)
 	}
 
 	// 5. read logs to find desired replica
-	desiredReplica, err := r.parseJobLogs(ctx, jobKey)
+	result, err := r.parseJobLogs(ctx, jobKey)
 	if err != nil {
-		return 0, fmt.Errorf("[parse-job-logs] %s", err)
+		return nil, fmt.Errorf("[parse-job-logs] %s", err)
 	}
 
-	return max(eifaReplica.Spec.MinReplicas, min(eifaReplica.Spec.MaxReplicas, desiredReplica)), nil
+	return result, nil
 
 }
 
-func (r *EifaReplicaReconciler) parseJobLogs(ctx context.Context, jobKey types.NamespacedName) (int32, error) {
+func (r *EifaReplicaReconciler) parseJobLogs(ctx context.Context, jobKey types.NamespacedName) (*jobResult, error) {
 	// Step 1: List Pods associated with the Job
 	podList := &corev1.PodList{}
 	if err := r.List(ctx, podList, client.InNamespace(jobKey.Namespace), client.MatchingLabels{"job-name": jobKey.Name}); err != nil {
-		return 0, fmt.Errorf("can not get list of pods: %w", err)
+		return nil, fmt.Errorf("can not get list of pods: %w", err)
 	}
 
 	// Step 2: Find Success Pod
@@ -107,14 +111,14 @@ func (r *EifaReplicaReconciler) parseJobLogs(ctx context.Context, jobKey types.N
 	}
 
 	if pod == nil {
-		return 0, fmt.Errorf("can not find success pod")
+		return nil, fmt.Errorf("can not find success pod")
 	}
 
 	// Step 3: Read Logs
 
 	clientset, err := kubernetes.NewForConfig(ctrl.GetConfigOrDie())
 	if err != nil {
-		return 0, fmt.Errorf("failed to create clientset: %s", err)
+		return nil, fmt.Errorf("failed to create clientset: %s", err)
 	}
 
 	tail := int64(1)
@@ -122,22 +126,17 @@ func (r *EifaReplicaReconciler) parseJobLogs(ctx context.Context, jobKey types.N
 	// Step 3: Stream logs from the pod
 	logs, err := req.Stream(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to stream logs: %s", err)
+		return nil, fmt.Errorf("failed to stream logs: %s", err)
 	}
 	defer logs.Close()
 
 	// Step 4: Read logs
 	logContent, err := io.ReadAll(logs)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read logs: %s", err)
-	}
-
-	desiredReplica, err := strconv.ParseInt(strings.TrimSpace((string(logContent))), 10, 32)
-	if err != nil {
-		return 0, fmt.Errorf("can not parse log to int, %s", err)
+		return nil, fmt.Errorf("failed to read logs: %s", err)
 	}
 
-	return int32(desiredReplica), nil
+	return parseJobOutput(string(logContent))
 
 }
 
@@ -152,3 +151,31 @@ func (r *EifaReplicaReconciler) checkJobStatus(job *batchv1.Job) string {
 	}
 	return schedulev1.JOB_RUNNING // Job is still running
 }
+
+// parseJobOutput parses the last line of the job logs
+func parseJobOutput(line string) (*jobResult, error) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "[") {
+		timeline, err := parseTimeline(line)
+		if err != nil {
+			return nil, err
+		}
+		return &jobResult{Timeline: timeline}, nil
+	}
+	if strings.HasPrefix(line, "{") {
+		result := &jobResult{}
+		if err := json.Unmarshal([]byte(line), result); err != nil {
+			return nil, fmt.Errorf("can not parse log to json, %s", err)
+		}
+		if result.TTLSeconds != nil && *result.TTLSeconds <= 0 {
+			return nil, fmt.Errorf("ttlSeconds must be greater than 0")
+		}
+		return result, nil
+	}
+
+	desiredReplica, err := strconv.ParseInt(line, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse log to int, %s", err)
+	}
+	return &jobResult{Replicas: int32(desiredReplica)}, nil
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/gorhill/cronexpr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// leadTime returns how long ahead of its scheduled time the job runs
+func leadTime(eifaReplica *schedulev1.EifaReplica) time.Duration {
+	if eifaReplica.Spec.LeadTime != nil && eifaReplica.Spec.LeadTime.Duration > 0 {
+		return eifaReplica.Spec.LeadTime.Duration
+	}
+	return 0
+}
+
+// nextRun returns when the job of the first scheduled time of cron after t runs, the lead time ahead of it
+func nextRun(eifaReplica *schedulev1.EifaReplica, cron *cronexpr.Expression, t time.Time) time.Time {
+	lead := leadTime(eifaReplica)
+	return cron.Next(t.Add(lead)).Add(-lead)
+}
+
+// holdResult holds the desired replicas computed by the job started at start until the scheduled time it ran
+// ahead of. It returns false, and drops the result held for an earlier run, when the job did not start within
+// the lead time ahead of a scheduled time or finished past it.
+func holdResult(eifaReplica *schedulev1.EifaReplica, cron *cronexpr.Expression, desired int32, start, now time.Time) bool {
+	eifaReplica.Status.PendingResult = nil
+	lead := leadTime(eifaReplica)
+	if lead <= 0 {
+		return false
+	}
+	scheduled := cron.Next(start)
+	if scheduled.Sub(start) > lead || !now.Before(scheduled) {
+		return false
+	}
+	eifaReplica.Status.PendingResult = &schedulev1.PendingResult{Replicas: desired, EffectiveTime: metav1.NewTime(scheduled)}
+	return true
+}
+
+// releaseResult returns the held result once its scheduled time is reached, clamped to the min and max
+// replicas in case they changed meanwhile
+func releaseResult(eifaReplica *schedulev1.EifaReplica, now time.Time) (int32, bool) {
+	pending := eifaReplica.Status.PendingResult
+	if pending == nil || now.Before(pending.EffectiveTime.Time) {
+		return 0, false
+	}
+	eifaReplica.Status.PendingResult = nil
+	return max(eifaReplica.Spec.MinReplicas, min(eifaReplica.Spec.MaxReplicas, pending.Replicas)), true
+}
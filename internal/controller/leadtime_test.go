@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/gorhill/cronexpr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("LeadTime", func() {
+	var er *schedulev1.EifaReplica
+	cron := cronexpr.MustParse("0 9 * * *")
+	nine := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+
+	BeforeEach(func() {
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			MinReplicas: 2,
+			MaxReplicas: 10,
+			LeadTime:    &metav1.Duration{Duration: 10 * time.Minute},
+		}}
+	})
+
+	It("should run the job ahead of the scheduled time", func() {
+		Expect(nextRun(er, cron, nine.Add(-time.Hour))).To(Equal(nine.Add(-10 * time.Minute)))
+		// the job of the scheduled time is running, the next one runs the day after
+		Expect(nextRun(er, cron, nine.Add(-9*time.Minute))).To(Equal(nine.Add(24*time.Hour - 10*time.Minute)))
+
+		er.Spec.LeadTime = nil
+		Expect(nextRun(er, cron, nine.Add(-9*time.Minute))).To(Equal(nine))
+	})
+
+	It("should hold the result until the scheduled time", func() {
+		start := nine.Add(-10 * time.Minute)
+		Expect(holdResult(er, cron, 8, start, start.Add(3*time.Minute))).To(BeTrue())
+		Expect(er.Status.PendingResult.EffectiveTime.Time).To(Equal(nine))
+
+		_, ok := releaseResult(er, nine.Add(-time.Second))
+		Expect(ok).To(BeFalse())
+		er.Spec.MaxReplicas = 6
+		replicas, ok := releaseResult(er, nine)
+		Expect(ok).To(BeTrue())
+		Expect(replicas).To(Equal(int32(6)))
+		Expect(er.Status.PendingResult).To(BeNil())
+	})
+
+	It("should apply the result of a job not run ahead right away", func() {
+		// the first job runs when the EifaReplica is created
+		Expect(holdResult(er, cron, 8, nine.Add(-time.Hour), nine.Add(-time.Hour))).To(BeFalse())
+		// the job ran past the scheduled time
+		Expect(holdResult(er, cron, 8, nine.Add(-10*time.Minute), nine.Add(time.Second))).To(BeFalse())
+
+		er.Spec.LeadTime = nil
+		er.Status.PendingResult = &schedulev1.PendingResult{Replicas: 4, EffectiveTime: metav1.NewTime(nine)}
+		Expect(holdResult(er, cron, 8, nine.Add(-time.Minute), nine)).To(BeFalse())
+		Expect(er.Status.PendingResult).To(BeNil())
+	})
+})
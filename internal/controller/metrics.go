@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var (
+	desiredReplicasGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "eifareplica_desired_replicas",
+		Help: "Replicas decided for the target of an EifaReplica, in Shadow mode the replicas which would have been written",
+	}, []string{"namespace", "name", "mode"})
+
+	targetReplicasGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "eifareplica_target_replicas",
+		Help: "Replicas of the target of an EifaReplica when it was last reconciled",
+	}, []string{"namespace", "name", "mode"})
+
+	hourlyCostGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "eifareplica_hourly_cost",
+		Help: "Estimated hourly cost of the target of an EifaReplica at its desired replicas",
+	}, []string{"namespace", "name", "mode"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(desiredReplicasGauge, targetReplicasGauge, hourlyCostGauge)
+}
+
+// modes are the values of the mode label
+var modes = []string{"", schedulev1.MODE_ACTIVE, schedulev1.MODE_METRIC, schedulev1.MODE_SHADOW}
+
+// forgetOtherModes deletes the series of eifaReplica exported by gauge before its mode changed
+func forgetOtherModes(gauge *prometheus.GaugeVec, eifaReplica *schedulev1.EifaReplica) {
+	for _, mode := range modes {
+		if mode != eifaReplica.Spec.Mode {
+			gauge.DeleteLabelValues(eifaReplica.Namespace, eifaReplica.Name, mode)
+		}
+	}
+}
+
+// observeReplicas exports the desired replicas and, when the target was fetched, its current replicas
+func observeReplicas(eifaReplica *schedulev1.EifaReplica, desired int32, current *int32) {
+	labels := prometheus.Labels{"namespace": eifaReplica.Namespace, "name": eifaReplica.Name, "mode": eifaReplica.Spec.Mode}
+	forgetOtherModes(desiredReplicasGauge, eifaReplica)
+	desiredReplicasGauge.With(labels).Set(float64(desired))
+	if current != nil {
+		forgetOtherModes(targetReplicasGauge, eifaReplica)
+		targetReplicasGauge.With(labels).Set(float64(*current))
+	}
+}
+
+// observeCost exports the estimated hourly cost of the target
+func observeCost(eifaReplica *schedulev1.EifaReplica, cost float64) {
+	forgetOtherModes(hourlyCostGauge, eifaReplica)
+	hourlyCostGauge.With(prometheus.Labels{
+		"namespace": eifaReplica.Namespace, "name": eifaReplica.Name, "mode": eifaReplica.Spec.Mode,
+	}).Set(cost)
+}
+
+// forgetCost deletes the estimated hourly cost of the target
+func forgetCost(key types.NamespacedName) {
+	hourlyCostGauge.DeletePartialMatch(prometheus.Labels{"namespace": key.Namespace, "name": key.Name})
+}
+
+// forgetReplicas deletes the metrics of a deleted EifaReplica
+func forgetReplicas(key types.NamespacedName) {
+	labels := prometheus.Labels{"namespace": key.Namespace, "name": key.Name}
+	desiredReplicasGauge.DeletePartialMatch(labels)
+	targetReplicasGauge.DeletePartialMatch(labels)
+	hourlyCostGauge.DeletePartialMatch(labels)
+}
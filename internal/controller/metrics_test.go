@@ -0,0 +1,49 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Metrics", func() {
+	It("should export the desired and target replicas", func() {
+		er := &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "metrics", Name: "shadow"},
+			Spec:       schedulev1.EifaReplicaSpec{Mode: schedulev1.MODE_SHADOW},
+		}
+		current := int32(4)
+		observeReplicas(er, 9, &current)
+		Expect(testutil.ToFloat64(desiredReplicasGauge.WithLabelValues("metrics", "shadow", "Shadow"))).To(Equal(9.0))
+		Expect(testutil.ToFloat64(targetReplicasGauge.WithLabelValues("metrics", "shadow", "Shadow"))).To(Equal(4.0))
+
+		// the gauges are shared by all the specs, only check the series of this EifaReplica
+		forgetReplicas(types.NamespacedName{Namespace: "metrics", Name: "shadow"})
+		Expect(desiredReplicasGauge.DeleteLabelValues("metrics", "shadow", "Shadow")).To(BeFalse())
+		Expect(targetReplicasGauge.DeleteLabelValues("metrics", "shadow", "Shadow")).To(BeFalse())
+	})
+
+	It("should forget the series of the previous mode", func() {
+		er := &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "metrics", Name: "switch"},
+			Spec:       schedulev1.EifaReplicaSpec{Mode: schedulev1.MODE_SHADOW},
+		}
+		current := int32(4)
+		observeReplicas(er, 9, &current)
+		observeCost(er, 1.5)
+
+		er.Spec.Mode = schedulev1.MODE_ACTIVE
+		observeReplicas(er, 7, &current)
+		observeCost(er, 2.5)
+		Expect(testutil.ToFloat64(desiredReplicasGauge.WithLabelValues("metrics", "switch", "Active"))).To(Equal(7.0))
+		Expect(desiredReplicasGauge.DeleteLabelValues("metrics", "switch", "Shadow")).To(BeFalse())
+		Expect(targetReplicasGauge.DeleteLabelValues("metrics", "switch", "Shadow")).To(BeFalse())
+		Expect(hourlyCostGauge.DeleteLabelValues("metrics", "switch", "Shadow")).To(BeFalse())
+
+		forgetReplicas(types.NamespacedName{Namespace: "metrics", Name: "switch"})
+	})
+})
@@ -0,0 +1,330 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// prewarmLabel selects the placeholder pods of an EifaReplica, its value is the name of the EifaReplica
+const prewarmLabel = "schedule.eifa.org/prewarm"
+
+// defaultPrewarmLead is the lead time when .Spec.Prewarm.LeadSeconds is unset
+const defaultPrewarmLead = 300 * time.Second
+
+// defaultPrewarmImage is the image of the placeholder containers when .Spec.Prewarm.Image is unset
+const defaultPrewarmImage = "registry.k8s.io/pause:3.10"
+
+// prewarmLead returns how long the placeholders run before the scale up is written
+func prewarmLead(eifaReplica *schedulev1.EifaReplica) time.Duration {
+	if prewarm := eifaReplica.Spec.Prewarm; prewarm != nil && prewarm.LeadSeconds > 0 {
+		return time.Duration(prewarm.LeadSeconds) * time.Second
+	}
+	return defaultPrewarmLead
+}
+
+// placeholderName returns the name of the deployment running the placeholders of eifaReplica
+func placeholderName(eifaReplica *schedulev1.EifaReplica) string {
+	return eifaReplica.Name + "-prewarm"
+}
+
+// requiresPrewarm reports whether the scale up of eifaReplica from current to desired is pre-warmed,
+// raising the max replicas of an autoscaler creates no pod
+func requiresPrewarm(eifaReplica *schedulev1.EifaReplica, current, desired int32) bool {
+	prewarm := eifaReplica.Spec.Prewarm
+	autoscalerMax := normalizeKind(eifaReplica.Spec.ScaleTargetRef.Kind) != kindDeployment &&
+		targetMode(eifaReplica) == schedulev1.MODE_MAX_REPLICAS
+	if prewarm == nil || autoscalerMax {
+		return false
+	}
+	return desired-current >= max(1, prewarm.MinReplicaChange)
+}
+
+// placeholderTemplate returns the pod template of the placeholders of the pods of template: pause containers
+// requesting the same resources on the same nodes, preempted by any pod of a higher priority
+func placeholderTemplate(eifaReplica *schedulev1.EifaReplica, template *corev1.PodTemplateSpec) corev1.PodTemplateSpec {
+	prewarm := eifaReplica.Spec.Prewarm
+	image := prewarm.Image
+	if image == "" {
+		image = defaultPrewarmImage
+	}
+	gracePeriod := int64(0)
+	automount := false
+	placeholder := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{prewarmLabel: eifaReplica.Name}},
+		Spec: corev1.PodSpec{
+			PriorityClassName:             prewarm.PriorityClassName,
+			TerminationGracePeriodSeconds: &gracePeriod,
+			AutomountServiceAccountToken:  &automount,
+			NodeSelector:                  template.Spec.NodeSelector,
+			Tolerations:                   template.Spec.Tolerations,
+		},
+	}
+	// the pod affinities of the target would select its own pods, only the nodes matter
+	if affinity := template.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil {
+		placeholder.Spec.Affinity = &corev1.Affinity{NodeAffinity: affinity.NodeAffinity}
+	}
+	for _, container := range template.Spec.Containers {
+		placeholder.Spec.Containers = append(placeholder.Spec.Containers, corev1.Container{
+			Name:      container.Name,
+			Image:     image,
+			Resources: *container.Resources.DeepCopy(),
+		})
+	}
+	return placeholder
+}
+
+// upcomingScaleUp returns the replicas of the next scale up of eifaReplica and when it is written, the held
+// job result or the next point of its timeline, false when none is written within the lead time
+func (r *EifaReplicaReconciler) upcomingScaleUp(ctx context.Context, eifaReplica *schedulev1.EifaReplica, now time.Time) (int32, time.Time, bool, error) {
+	lead := prewarmLead(eifaReplica)
+	var to int32
+	var at time.Time
+	if pending := eifaReplica.Status.PendingResult; pending != nil && now.Before(pending.EffectiveTime.Time) {
+		to, at = pending.Replicas, pending.EffectiveTime.Time
+	}
+	if timeline := eifaReplica.Status.Timeline; timeline != nil && timeline.NextTime != nil && now.Before(timeline.NextTime.Time) &&
+		!now.Before(timeline.NextTime.Add(-lead)) && (at.IsZero() || timeline.NextTime.Time.Before(at)) {
+		points, err := r.timelinePoints(ctx, eifaReplica)
+		if err != nil {
+			return 0, time.Time{}, false, err
+		}
+		if int(timeline.Applied) < len(points) {
+			to, at = points[timeline.Applied].Replicas, timeline.NextTime.Time
+		}
+	}
+	if at.IsZero() || now.Before(at.Add(-lead)) {
+		return 0, time.Time{}, false, nil
+	}
+	return max(eifaReplica.Spec.MinReplicas, min(eifaReplica.Spec.MaxReplicas, to)), at, true, nil
+}
+
+// prewarm runs placeholder pods for the capacity of the upcoming scale up of eifaReplica during the lead time
+// before it is written, the scale up itself is written on schedule. The placeholders are resized by a new job
+// result, released when the scale up is cancelled and scaled down as its pods land once it is written.
+func (r *EifaReplicaReconciler) prewarm(ctx context.Context, eifaReplica *schedulev1.EifaReplica, now time.Time, next *time.Time) error {
+	state := eifaReplica.Status.Prewarm
+	if state != nil && !now.Before(state.ApplyTime.Time) {
+		return r.releaseLanded(ctx, eifaReplica, now, next)
+	}
+
+	var running, to, placeholders int32
+	var at time.Time
+	var template *corev1.PodTemplateSpec
+	mode := eifaReplica.Spec.Mode
+	if mode != schedulev1.MODE_METRIC && mode != schedulev1.MODE_SHADOW && eifaReplica.Spec.Prewarm != nil {
+		var ok bool
+		var err error
+		to, at, ok, err = r.upcomingScaleUp(ctx, eifaReplica, now)
+		if err != nil {
+			return fmt.Errorf("can not get the upcoming scale up, %s", err)
+		}
+		if ok {
+			target, err := r.getScaleTarget(ctx, eifaReplica)
+			if err != nil {
+				return fmt.Errorf("can not get the target, %s", err)
+			}
+			// the placeholders stand for the pods the scale up creates in the deployment behind the target
+			deployment, err := r.workload(ctx, eifaReplica.Namespace, target)
+			if err != nil {
+				return fmt.Errorf("can not get the deployment of the target, %s", err)
+			}
+			if deployment != nil {
+				running = 1
+				if deployment.Spec.Replicas != nil {
+					running = *deployment.Spec.Replicas
+				}
+				if requiresPrewarm(eifaReplica, running, to) {
+					placeholders = to - running
+					template = &deployment.Spec.Template
+				}
+			}
+		}
+	}
+
+	if placeholders == 0 {
+		if state == nil {
+			return nil
+		}
+		if err := r.releasePlaceholders(ctx, eifaReplica); err != nil {
+			return err
+		}
+		return r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.PREWARMING,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "PlaceholdersReleased",
+			Message:            fmt.Sprintf("released the placeholders of the cancelled scale up from %d to %d", state.From, state.To),
+		}, next)
+	}
+	if state != nil && state.To == to && state.Placeholders == placeholders && state.ApplyTime.Time.Equal(at) {
+		return nil
+	}
+
+	if err := r.applyPlaceholders(ctx, eifaReplica, template, placeholders); err != nil {
+		return err
+	}
+	eifaReplica.Status.Prewarm = &schedulev1.PrewarmStatus{From: running, To: to, Placeholders: placeholders, ApplyTime: metav1.NewTime(at)}
+	msg := fmt.Sprintf("change of target replica from %d to %d is written at %s, %d placeholder pods provision its capacity",
+		running, to, at.Format(time.RFC3339), placeholders)
+	r.Recorder.Event(eifaReplica, corev1.EventTypeNormal, "Prewarming", msg)
+	return r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+		Type:               schedulev1.PREWARMING,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "PlaceholdersCreated",
+		Message:            msg,
+	}, next)
+}
+
+// applyPlaceholders runs placeholders pods of template in the placeholder deployment of eifaReplica, a deployment
+// of the same name which is not controlled by eifaReplica is never taken over
+func (r *EifaReplicaReconciler) applyPlaceholders(ctx context.Context, eifaReplica *schedulev1.EifaReplica, template *corev1.PodTemplateSpec, placeholders int32) error {
+	deployment := &appsv1.Deployment{}
+	key := client.ObjectKey{Namespace: eifaReplica.Namespace, Name: placeholderName(eifaReplica)}
+	err := r.Get(ctx, key, deployment)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("can not get the placeholder deployment %s, %s", key.Name, err)
+	}
+	found := err == nil
+	if found && !metav1.IsControlledBy(deployment, eifaReplica) {
+		return fmt.Errorf("deployment %s already exists and is not controlled by EifaReplica %s", key.Name, eifaReplica.Name)
+	}
+
+	deployment.Name, deployment.Namespace = key.Name, key.Namespace
+	deployment.Spec.Replicas = &placeholders
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{prewarmLabel: eifaReplica.Name}}
+	deployment.Spec.Template = placeholderTemplate(eifaReplica, template)
+	if found {
+		if err := r.Update(ctx, deployment); err != nil {
+			return fmt.Errorf("can not update the placeholder deployment %s, %s", key.Name, err)
+		}
+		return nil
+	}
+	if err := ctrl.SetControllerReference(eifaReplica, deployment, r.Scheme); err != nil {
+		return fmt.Errorf("can not set the owner of the placeholder deployment %s, %s", key.Name, err)
+	}
+	if err := r.Create(ctx, deployment); err != nil {
+		return fmt.Errorf("can not create the placeholder deployment %s, %s", key.Name, err)
+	}
+	return nil
+}
+
+// placeholders returns the placeholder deployment of eifaReplica, nil when there is none controlled by it
+func (r *EifaReplicaReconciler) placeholders(ctx context.Context, eifaReplica *schedulev1.EifaReplica) (*appsv1.Deployment, error) {
+	deployment := &appsv1.Deployment{}
+	key := client.ObjectKey{Namespace: eifaReplica.Namespace, Name: placeholderName(eifaReplica)}
+	if err := r.Get(ctx, key, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("can not get the placeholder deployment %s, %s", key.Name, err)
+	}
+	if !metav1.IsControlledBy(deployment, eifaReplica) {
+		return nil, nil
+	}
+	return deployment, nil
+}
+
+// releasePlaceholders deletes the placeholders of eifaReplica
+func (r *EifaReplicaReconciler) releasePlaceholders(ctx context.Context, eifaReplica *schedulev1.EifaReplica) error {
+	if eifaReplica.Status.Prewarm == nil {
+		return nil
+	}
+	deployment, err := r.placeholders(ctx, eifaReplica)
+	if err != nil {
+		return err
+	}
+	if deployment != nil {
+		if err := r.Delete(ctx, deployment, client.PropagationPolicy(metav1.DeletePropagationBackground),
+			client.Preconditions{UID: &deployment.UID}); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("can not delete the placeholder deployment %s, %s", deployment.Name, err)
+		}
+	}
+	eifaReplica.Status.Prewarm = nil
+	return nil
+}
+
+// landedPlaceholders returns the placeholders still needed once the scale up of state was written to the
+// deployment behind the target: one for each replica of the scale up not ready yet. None are needed once
+// the pods of the target had the lead time to land, nor once the scale up is written in a namespace with
+// unscoped ResourceQuotas, which would reject the pods of the target while the placeholders hold their share.
+func landedPlaceholders(state *schedulev1.PrewarmStatus, deployment *appsv1.Deployment, quotaLimited bool, deadline, now time.Time) int32 {
+	if deployment == nil || !now.Before(deadline) {
+		return 0
+	}
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas > state.From && quotaLimited {
+		return 0
+	}
+	landed := max(0, deployment.Status.ReadyReplicas-state.From)
+	return min(state.Placeholders, max(0, state.To-state.From-landed))
+}
+
+// releaseLanded scales the placeholders of the scale up written at its apply time down as the pods of the target
+// become ready, they are deleted once none is needed anymore or eifaReplica no longer writes the target
+func (r *EifaReplicaReconciler) releaseLanded(ctx context.Context, eifaReplica *schedulev1.EifaReplica, now time.Time, next *time.Time) error {
+	state := eifaReplica.Status.Prewarm
+	mode := eifaReplica.Spec.Mode
+	writing := mode != schedulev1.MODE_METRIC && mode != schedulev1.MODE_SHADOW && eifaReplica.Spec.Prewarm != nil
+
+	var placeholders int32
+	if writing {
+		target, err := r.getScaleTarget(ctx, eifaReplica)
+		if err != nil {
+			return fmt.Errorf("can not get the target, %s", err)
+		}
+		deployment, err := r.workload(ctx, eifaReplica.Namespace, target)
+		if err != nil {
+			return fmt.Errorf("can not get the deployment of the target, %s", err)
+		}
+		quotas := &corev1.ResourceQuotaList{}
+		if err := r.List(ctx, quotas, client.InNamespace(eifaReplica.Namespace)); err != nil {
+			return fmt.Errorf("can not list resourcequotas, %s", err)
+		}
+		quotaLimited := false
+		for i := range quotas.Items {
+			quotaLimited = quotaLimited || !scopedQuota(&quotas.Items[i])
+		}
+		deadline := state.ApplyTime.Add(prewarmLead(eifaReplica))
+		placeholders = landedPlaceholders(state, deployment, quotaLimited, deadline, now)
+	}
+	if placeholders == state.Placeholders {
+		return nil
+	}
+
+	if placeholders == 0 {
+		if err := r.releasePlaceholders(ctx, eifaReplica); err != nil {
+			return err
+		}
+		return r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.PREWARMING,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "PlaceholdersReleased",
+			Message:            fmt.Sprintf("released the placeholders of the scale up from %d to %d", state.From, state.To),
+		}, next)
+	}
+	deployment, err := r.placeholders(ctx, eifaReplica)
+	if err != nil {
+		return err
+	}
+	if deployment == nil {
+		eifaReplica.Status.Prewarm = nil
+		return r.UpdateStatus(ctx, eifaReplica, nil, next)
+	}
+	deployment.Spec.Replicas = &placeholders
+	if err := r.Update(ctx, deployment); err != nil {
+		return fmt.Errorf("can not scale the placeholder deployment %s to %d, %s", deployment.Name, placeholders, err)
+	}
+	state.Placeholders = placeholders
+	return r.UpdateStatus(ctx, eifaReplica, nil, next)
+}
@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Prewarm", func() {
+	ctx := context.Background()
+	now := time.Now()
+	var er *schedulev1.EifaReplica
+	var deployment *appsv1.Deployment
+	var reconciler *EifaReplicaReconciler
+
+	placeholders := func() *appsv1.Deployment {
+		placeholder := &appsv1.Deployment{}
+		Expect(reconciler.Get(ctx, client.ObjectKey{Namespace: "default", Name: "web-prewarm"}, placeholder)).To(Succeed())
+		return placeholder
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(schedulev1.AddToScheme(scheme)).To(Succeed())
+
+		er = &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+			Spec: schedulev1.EifaReplicaSpec{
+				ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "Deployment", Name: "web"},
+				MaxReplicas:    20,
+				Prewarm:        &schedulev1.Prewarm{LeadSeconds: 120, MinReplicaChange: 5, PriorityClassName: "placeholder"},
+			},
+		}
+		replicas := int32(4)
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+					Spec: corev1.PodSpec{
+						NodeSelector: map[string]string{"pool": "web"},
+						Affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{TopologyKey: "kubernetes.io/hostname"}},
+						}},
+						Containers: []corev1.Container{{
+							Name:  "web",
+							Image: "web:1.0",
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+							},
+						}},
+					},
+				},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 4},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(er, deployment).
+			WithStatusSubresource(er).Build()
+		reconciler = &EifaReplicaReconciler{Client: c, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+	})
+
+	It("should only pre-warm large scale ups of pods", func() {
+		Expect(requiresPrewarm(er, 4, 8)).To(BeFalse())
+		Expect(requiresPrewarm(er, 4, 9)).To(BeTrue())
+		Expect(requiresPrewarm(er, 9, 4)).To(BeFalse())
+
+		er.Spec.ScaleTargetRef = schedulev1.ScaleTargetRef{Kind: "HorizontalPodAutoscaler", Name: "web", Mode: schedulev1.MODE_MAX_REPLICAS}
+		Expect(requiresPrewarm(er, 4, 9)).To(BeFalse())
+	})
+
+	It("should size the placeholders from the pod template", func() {
+		template := placeholderTemplate(er, &deployment.Spec.Template)
+		Expect(template.Labels).To(Equal(map[string]string{prewarmLabel: "web"}))
+		Expect(template.Spec.PriorityClassName).To(Equal("placeholder"))
+		Expect(template.Spec.NodeSelector).To(Equal(map[string]string{"pool": "web"}))
+		Expect(template.Spec.Affinity).To(BeNil())
+		Expect(template.Spec.Containers).To(HaveLen(1))
+		Expect(template.Spec.Containers[0].Image).To(Equal(defaultPrewarmImage))
+		Expect(template.Spec.Containers[0].Resources.Requests.Cpu().String()).To(Equal("500m"))
+	})
+
+	It("should pre-warm a held result within the lead time", func() {
+		er.Status.PendingResult = &schedulev1.PendingResult{Replicas: 10, EffectiveTime: metav1.NewTime(now.Add(3 * time.Minute))}
+
+		Expect(reconciler.prewarm(ctx, er, now, nil)).To(Succeed())
+		Expect(er.Status.Prewarm).To(BeNil())
+
+		Expect(reconciler.prewarm(ctx, er, now.Add(time.Minute), nil)).To(Succeed())
+		Expect(er.Status.Prewarm.From).To(Equal(int32(4)))
+		Expect(er.Status.Prewarm.Placeholders).To(Equal(int32(6)))
+		Expect(er.Status.Prewarm.ApplyTime.Time).To(BeTemporally("==", er.Status.PendingResult.EffectiveTime.Time))
+		Expect(er.Status.DeferredUntil).To(BeNil())
+		Expect(*placeholders().Spec.Replicas).To(Equal(int32(6)))
+		Expect(placeholders().OwnerReferences).To(HaveLen(1))
+
+		// a new job result resizes the placeholders
+		er.Status.PendingResult.Replicas = 12
+		Expect(reconciler.prewarm(ctx, er, now.Add(2*time.Minute), nil)).To(Succeed())
+		Expect(*placeholders().Spec.Replicas).To(Equal(int32(8)))
+	})
+
+	It("should pre-warm the next point of the timeline", func() {
+		Expect(reconciler.storeTimeline(ctx, er, []schedulev1.TimelinePoint{
+			{At: metav1.NewTime(now.Add(-time.Hour)), Replicas: 4},
+			{At: metav1.NewTime(now.Add(time.Minute)), Replicas: 11},
+		})).To(Succeed())
+		nextPoint(er.Status.Timeline, er.Status.Timeline.Points, now)
+
+		Expect(reconciler.prewarm(ctx, er, now, nil)).To(Succeed())
+		Expect(er.Status.Prewarm.To).To(Equal(int32(11)))
+		Expect(er.Status.Prewarm.ApplyTime.Time).To(BeTemporally("==", er.Status.Timeline.NextTime.Time))
+		Expect(*placeholders().Spec.Replicas).To(Equal(int32(7)))
+	})
+
+	It("should release the placeholders of a cancelled scale up", func() {
+		er.Status.PendingResult = &schedulev1.PendingResult{Replicas: 10, EffectiveTime: metav1.NewTime(now.Add(time.Minute))}
+		Expect(reconciler.prewarm(ctx, er, now, nil)).To(Succeed())
+
+		er.Status.PendingResult.Replicas = 6
+		Expect(reconciler.prewarm(ctx, er, now.Add(30*time.Second), nil)).To(Succeed())
+		Expect(er.Status.Prewarm).To(BeNil())
+		Expect(er.Status.Conditions[len(er.Status.Conditions)-1].Reason).To(Equal("PlaceholdersReleased"))
+		err := reconciler.Get(ctx, client.ObjectKey{Namespace: "default", Name: "web-prewarm"}, &appsv1.Deployment{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not take over a deployment it does not control", func() {
+		replicas := int32(2)
+		foreign := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-prewarm", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+		Expect(reconciler.Create(ctx, foreign)).To(Succeed())
+		er.Status.PendingResult = &schedulev1.PendingResult{Replicas: 10, EffectiveTime: metav1.NewTime(now.Add(time.Minute))}
+
+		Expect(reconciler.prewarm(ctx, er, now, nil)).To(MatchError(ContainSubstring("not controlled by EifaReplica web")))
+		Expect(er.Status.Prewarm).To(BeNil())
+		Expect(*placeholders().Spec.Replicas).To(Equal(int32(2)))
+
+		// nor deletes it when releasing the placeholders
+		er.Status.Prewarm = &schedulev1.PrewarmStatus{From: 4, To: 10, Placeholders: 6, ApplyTime: metav1.NewTime(now.Add(time.Minute))}
+		Expect(reconciler.releasePlaceholders(ctx, er)).To(Succeed())
+		Expect(er.Status.Prewarm).To(BeNil())
+		Expect(placeholders().UID).To(Equal(foreign.UID))
+	})
+
+	It("should count the placeholders landed", func() {
+		state := &schedulev1.PrewarmStatus{From: 4, To: 10, Placeholders: 6}
+		deadline := now.Add(time.Minute)
+		replicas := int32(10)
+		deployment.Spec.Replicas = &replicas
+		deployment.Status.ReadyReplicas = 7
+		Expect(landedPlaceholders(state, deployment, false, deadline, now)).To(Equal(int32(3)))
+		Expect(landedPlaceholders(state, deployment, false, deadline, deadline)).To(Equal(int32(0)))
+		Expect(landedPlaceholders(state, deployment, true, deadline, now)).To(Equal(int32(0)))
+
+		// the placeholders are never scaled back up
+		state.Placeholders = 2
+		Expect(landedPlaceholders(state, deployment, false, deadline, now)).To(Equal(int32(2)))
+	})
+
+	It("should scale the placeholders down as the pods land", func() {
+		er.Status.PendingResult = &schedulev1.PendingResult{Replicas: 10, EffectiveTime: metav1.NewTime(now.Add(time.Minute))}
+		Expect(reconciler.prewarm(ctx, er, now, nil)).To(Succeed())
+		er.Status.PendingResult = nil
+
+		// the scale up is written at its time
+		replicas := int32(10)
+		deployment.Spec.Replicas = &replicas
+		Expect(reconciler.Update(ctx, deployment)).To(Succeed())
+		deployment.Status.ReadyReplicas = 7
+		Expect(reconciler.Status().Update(ctx, deployment)).To(Succeed())
+		Expect(reconciler.prewarm(ctx, er, now.Add(2*time.Minute), nil)).To(Succeed())
+		Expect(er.Status.Prewarm.Placeholders).To(Equal(int32(3)))
+		Expect(*placeholders().Spec.Replicas).To(Equal(int32(3)))
+
+		deployment.Status.ReadyReplicas = 10
+		Expect(reconciler.Status().Update(ctx, deployment)).To(Succeed())
+		Expect(reconciler.prewarm(ctx, er, now.Add(150*time.Second), nil)).To(Succeed())
+		Expect(er.Status.Prewarm).To(BeNil())
+		Expect(er.Status.Conditions[len(er.Status.Conditions)-1].Reason).To(Equal("PlaceholdersReleased"))
+	})
+
+	It("should ignore scoped quotas when releasing the placeholders", func() {
+		replicas := int32(10)
+		deployment.Spec.Replicas = &replicas
+		Expect(reconciler.Update(ctx, deployment)).To(Succeed())
+		deployment.Status.ReadyReplicas = 7
+		Expect(reconciler.Status().Update(ctx, deployment)).To(Succeed())
+		Expect(reconciler.Create(ctx, &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "best-effort", Namespace: "default"},
+			Spec:       corev1.ResourceQuotaSpec{Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}},
+		})).To(Succeed())
+		Expect(reconciler.applyPlaceholders(ctx, er, &deployment.Spec.Template, 6)).To(Succeed())
+		er.Status.Prewarm = &schedulev1.PrewarmStatus{From: 4, To: 10, Placeholders: 6, ApplyTime: metav1.NewTime(now)}
+
+		Expect(reconciler.prewarm(ctx, er, now, nil)).To(Succeed())
+		Expect(er.Status.Prewarm.Placeholders).To(Equal(int32(3)))
+
+		// an unscoped quota would reject the pods of the target
+		Expect(reconciler.Create(ctx, &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: "default"}})).To(Succeed())
+		Expect(reconciler.prewarm(ctx, er, now, nil)).To(Succeed())
+		Expect(er.Status.Prewarm).To(BeNil())
+	})
+})
@@ -0,0 +1,433 @@
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// quotaRetryInterval is the delay before retrying the part of a scale up exceeding a budget
+const quotaRetryInterval = 30 * time.Second
+
+// quotaUsage is the consumption of the budgets of a namespace
+type quotaUsage struct {
+	replicas int32
+	cpu      resource.Quantity
+	memory   resource.Quantity
+}
+
+// add accounts for replicas pods requesting requests each
+func (u *quotaUsage) add(replicas int32, requests corev1.ResourceList) {
+	u.replicas += replicas
+	for i := int32(0); i < replicas; i++ {
+		u.cpu.Add(requests[corev1.ResourceCPU])
+		u.memory.Add(requests[corev1.ResourceMemory])
+	}
+}
+
+// podRequests returns the cpu and memory requested by a pod of template
+func podRequests(template *corev1.PodTemplateSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	if template == nil {
+		return requests
+	}
+	for _, container := range template.Spec.Containers {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if quantity, ok := container.Resources.Requests[name]; ok {
+				sum := requests[name]
+				sum.Add(quantity)
+				requests[name] = sum
+			}
+		}
+	}
+	return requests
+}
+
+// quotaBudget is the rest of the budgets of an EifaReplicaQuota, a negative value is unlimited
+type quotaBudget struct {
+	replicas int64
+	milliCPU int64
+	memory   int64
+}
+
+func newQuotaBudget(quota *schedulev1.EifaReplicaQuota) quotaBudget {
+	budget := quotaBudget{replicas: -1, milliCPU: -1, memory: -1}
+	if quota.Spec.MaxReplicas != nil {
+		budget.replicas = int64(*quota.Spec.MaxReplicas)
+	}
+	if quota.Spec.MaxCPU != nil {
+		budget.milliCPU = quota.Spec.MaxCPU.MilliValue()
+	}
+	if quota.Spec.MaxMemory != nil {
+		budget.memory = quota.Spec.MaxMemory.Value()
+	}
+	return budget
+}
+
+// fit returns how many pods requesting requests fit in the budget
+func (b *quotaBudget) fit(requests corev1.ResourceList) int64 {
+	fit := int64(math.MaxInt32)
+	for _, dim := range []struct{ left, request int64 }{
+		{b.replicas, 1},
+		{b.milliCPU, requests.Cpu().MilliValue()},
+		{b.memory, requests.Memory().Value()},
+	} {
+		if dim.left >= 0 && dim.request > 0 {
+			fit = min(fit, dim.left/dim.request)
+		}
+	}
+	return fit
+}
+
+// take consumes replicas pods requesting requests, an unlimited budget stays unlimited and an exceeded one empty
+func (b *quotaBudget) take(replicas int32, requests corev1.ResourceList) {
+	for _, dim := range []struct {
+		left    *int64
+		request int64
+	}{
+		{&b.replicas, 1},
+		{&b.milliCPU, requests.Cpu().MilliValue()},
+		{&b.memory, requests.Memory().Value()},
+	} {
+		if *dim.left >= 0 {
+			*dim.left = max(0, *dim.left-int64(replicas)*dim.request)
+		}
+	}
+}
+
+// quotaDemand is the replicas an EifaReplica of the namespace drives and wants to drive
+type quotaDemand struct {
+	name     string
+	priority int32
+	current  int32
+	desired  int32
+	requests corev1.ResourceList
+}
+
+// allocate arbitrates the budgets of quota between demands and returns the replicas allowed to each of them.
+// Levels of priority are served from the highest one, by name within a level: every EifaReplica of a level
+// first keeps the replicas it already drives, trimmed to what the higher levels left, then its scale up is
+// served from the rest. The replicas driven are never trimmed in favor of an EifaReplica of the same
+// priority, and a scale up not fully served is dropped by the Defer action.
+func allocate(quota *schedulev1.EifaReplicaQuota, demands []quotaDemand) map[string]int32 {
+	sorted := slices.Clone(demands)
+	slices.SortFunc(sorted, func(a, b quotaDemand) int {
+		if a.priority != b.priority {
+			return cmp.Compare(b.priority, a.priority)
+		}
+		return cmp.Compare(a.name, b.name)
+	})
+
+	allocation := map[string]int32{}
+	budget := newQuotaBudget(quota)
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].priority == sorted[start].priority {
+			end++
+		}
+		level := sorted[start:end]
+
+		// what the higher levels left is shared by the replicas the level already drives
+		left := budget
+		for _, demand := range level {
+			held := min(demand.current, demand.desired, int32(left.fit(demand.requests)))
+			allocation[demand.name] = held
+			budget.take(held, demand.requests)
+		}
+		for _, demand := range level {
+			held := allocation[demand.name]
+			if demand.desired <= held {
+				continue
+			}
+			granted := min(demand.desired-held, int32(budget.fit(demand.requests)))
+			if granted < demand.desired-held && quota.Spec.Action == schedulev1.QUOTA_DEFER {
+				granted = 0
+			}
+			allocation[demand.name] = held + granted
+			budget.take(granted, demand.requests)
+		}
+		start = end
+	}
+	return allocation
+}
+
+// explainAllocation describes the EifaReplicas served before name by quota
+func explainAllocation(quota *schedulev1.EifaReplicaQuota, demands []quotaDemand, allocation map[string]int32, name string) string {
+	var self quotaDemand
+	for _, demand := range demands {
+		if demand.name == name {
+			self = demand
+		}
+	}
+	served := []string{}
+	for _, demand := range demands {
+		if demand.name != name && allocation[demand.name] > 0 &&
+			(demand.priority > self.priority || (demand.priority == self.priority && demand.name < name)) {
+			served = append(served, fmt.Sprintf("%s(priority %d, %d replicas)", demand.name, demand.priority, allocation[demand.name]))
+		}
+	}
+	slices.Sort(served)
+	if len(served) == 0 {
+		return fmt.Sprintf("EifaReplicaQuota %s allows %d of %d replicas", quota.Name, allocation[name], self.desired)
+	}
+	return fmt.Sprintf("EifaReplicaQuota %s allows %d of %d replicas after serving %s",
+		quota.Name, allocation[name], self.desired, strings.Join(served, ", "))
+}
+
+// workloadRef returns the kind and name of the workload holding the pods scaled through target
+func workloadRef(target scaleTarget) (string, string) {
+	var kind, name string
+	switch t := target.(type) {
+	case *deploymentTarget:
+		kind, name = kindDeployment, t.deployment.Name
+	case *hpaTarget:
+		kind, name = t.hpa.Spec.ScaleTargetRef.Kind, t.hpa.Spec.ScaleTargetRef.Name
+	case *scaledObjectTarget:
+		kind, _, _ = unstructured.NestedString(t.scaledObject.Object, "spec", "scaleTargetRef", "kind")
+		name, _, _ = unstructured.NestedString(t.scaledObject.Object, "spec", "scaleTargetRef", "name")
+		if kind == "" {
+			// KEDA scales a deployment when the kind is omitted
+			kind = "Deployment"
+		}
+	}
+	return kind, name
+}
+
+// workload returns the deployment scaled through target, nil when the workload is not a deployment
+func (r *EifaReplicaReconciler) workload(ctx context.Context, namespace string, target scaleTarget) (*appsv1.Deployment, error) {
+	if t, ok := target.(*deploymentTarget); ok {
+		return t.deployment, nil
+	}
+	kind, name := workloadRef(target)
+	if normalizeKind(kind) != kindDeployment || name == "" {
+		return nil, nil
+	}
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, deployment); err != nil {
+		return nil, err
+	}
+	return deployment, nil
+}
+
+// podTemplate returns the pod template of the workload scaled through target, nil when the workload
+// is not a deployment
+func (r *EifaReplicaReconciler) podTemplate(ctx context.Context, namespace string, target scaleTarget) (*corev1.PodTemplateSpec, error) {
+	deployment, err := r.workload(ctx, namespace, target)
+	if err != nil || deployment == nil {
+		return nil, err
+	}
+	return &deployment.Spec.Template, nil
+}
+
+// applyQuotas arbitrates the budgets of the namespace of eifaReplica, changing from current to desired, and
+// records the usage and the constrained EifaReplicas in their status. It returns the replicas allowed, which
+// are lower than current when eifaReplica is trimmed in favor of a higher priority, and the quota limiting
+// them, nil when desired fits. EifaReplicas in Metric or Shadow mode write nothing and are not accounted for,
+// the allocation of an EifaReplica in Shadow mode is computed without being recorded.
+func (r *EifaReplicaReconciler) applyQuotas(ctx context.Context, eifaReplica *schedulev1.EifaReplica, target scaleTarget, current, desired int32) (int32, *schedulev1.EifaReplicaQuota, error) {
+	eifaReplica.Status.Allocation = nil
+	quotas := &schedulev1.EifaReplicaQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(eifaReplica.Namespace)); err != nil {
+		return desired, nil, fmt.Errorf("can not list eifareplicaquotas, %s", err)
+	}
+	if len(quotas.Items) == 0 {
+		return desired, nil, nil
+	}
+
+	template, err := r.podTemplate(ctx, eifaReplica.Namespace, target)
+	if err != nil {
+		return desired, nil, fmt.Errorf("can not get the pod template of the target, %s", err)
+	}
+	demands := []quotaDemand{{
+		name:     eifaReplica.Name,
+		priority: priority(eifaReplica),
+		current:  current,
+		desired:  desired,
+		requests: podRequests(template),
+	}}
+
+	eifaReplicas := &schedulev1.EifaReplicaList{}
+	if err := r.List(ctx, eifaReplicas, client.InNamespace(eifaReplica.Namespace)); err != nil {
+		return desired, nil, fmt.Errorf("can not list eifareplicas, %s", err)
+	}
+	for i := range eifaReplicas.Items {
+		other := &eifaReplicas.Items[i]
+		if other.Name == eifaReplica.Name || other.Spec.Mode == schedulev1.MODE_METRIC || other.Spec.Mode == schedulev1.MODE_SHADOW {
+			continue
+		}
+		otherTarget, err := r.getScaleTarget(ctx, other)
+		if err != nil {
+			// a missing target drives no replicas
+			continue
+		}
+		template, err := r.podTemplate(ctx, other.Namespace, otherTarget)
+		if err != nil {
+			continue
+		}
+		demand := quotaDemand{
+			name:     other.Name,
+			priority: priority(other),
+			current:  otherTarget.Replicas(),
+			requests: podRequests(template),
+		}
+		demand.desired = demand.current
+		if other.Status.DesiredReplicas != nil {
+			demand.desired = *other.Status.DesiredReplicas
+		}
+		demands = append(demands, demand)
+	}
+
+	allowed := desired
+	var limiting *schedulev1.EifaReplicaQuota
+	for i := range quotas.Items {
+		quota := &quotas.Items[i]
+		allocation := allocate(quota, demands)
+		if allocation[eifaReplica.Name] < allowed {
+			allowed = allocation[eifaReplica.Name]
+			limiting = quota
+			eifaReplica.Status.Allocation = &schedulev1.QuotaAllocation{
+				Quota:     quota.Name,
+				Desired:   desired,
+				Allocated: allowed,
+				Message:   explainAllocation(quota, demands, allocation, eifaReplica.Name),
+			}
+		}
+		if eifaReplica.Spec.Mode != schedulev1.MODE_SHADOW {
+			r.recordQuotaUsage(ctx, quota, demands, allocation, eifaReplica.Name)
+		}
+	}
+	return allowed, limiting, nil
+}
+
+// recordQuotaUsage records in the status of quota the replicas driven once self writes its allocation
+// and the EifaReplicas allocated less than their desired replicas, the others pick up the change of
+// their allocation through the watch on the quotas
+func (r *EifaReplicaReconciler) recordQuotaUsage(ctx context.Context, quota *schedulev1.EifaReplicaQuota, demands []quotaDemand, allocation map[string]int32, self string) {
+	var usage quotaUsage
+	previous := map[string]schedulev1.QuotaConstraint{}
+	for _, constraint := range quota.Status.Constrained {
+		previous[constraint.Name] = constraint
+	}
+	constrained := []schedulev1.QuotaConstraint{}
+	for _, demand := range demands {
+		replicas := demand.current
+		if demand.name == self {
+			replicas = allocation[demand.name]
+		}
+		usage.add(replicas, demand.requests)
+
+		if allocation[demand.name] >= demand.desired {
+			continue
+		}
+		constraint := schedulev1.QuotaConstraint{
+			Name:     demand.name,
+			Desired:  demand.desired,
+			Allowed:  allocation[demand.name],
+			Priority: demand.priority,
+			Time:     metav1.Now(),
+		}
+		if last, ok := previous[demand.name]; ok && last.Desired == constraint.Desired &&
+			last.Allowed == constraint.Allowed && last.Priority == constraint.Priority {
+			constraint.Time = last.Time
+		}
+		constrained = append(constrained, constraint)
+	}
+	slices.SortFunc(constrained, func(a, b schedulev1.QuotaConstraint) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	status := quota.Status.DeepCopy()
+	status.UsedReplicas = usage.replicas
+	status.UsedCPU, status.UsedMemory = nil, nil
+	if quota.Spec.MaxCPU != nil {
+		status.UsedCPU = &usage.cpu
+	}
+	if quota.Spec.MaxMemory != nil {
+		status.UsedMemory = &usage.memory
+	}
+	status.Constrained = constrained
+	if equality.Semantic.DeepEqual(&quota.Status, status) {
+		return
+	}
+	quota.Status = *status
+	if err := r.Status().Update(ctx, quota); err != nil {
+		// the usage is recomputed on the next scale of any EifaReplica of the namespace
+		log.FromContext(ctx).Error(err, "Failed to update EifaReplicaQuota status", "quota", quota.Name)
+	}
+}
+
+// priority returns the priority of eifaReplica in the arbitration of the quotas
+func priority(eifaReplica *schedulev1.EifaReplica) int32 {
+	if eifaReplica.Spec.Priority == nil {
+		return 0
+	}
+	return *eifaReplica.Spec.Priority
+}
+
+// allocationDue reports whether the arbitration of a quota of the namespace, made by another EifaReplica,
+// changed the replicas allowed to eifaReplica: it is trimmed further, allowed more, or no longer limited
+func (r *EifaReplicaReconciler) allocationDue(ctx context.Context, eifaReplica *schedulev1.EifaReplica) bool {
+	if eifaReplica.Status.DesiredReplicas == nil {
+		return false
+	}
+	quotas := &schedulev1.EifaReplicaQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(eifaReplica.Namespace)); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list EifaReplicaQuotas, the allocation is checked on the next reconcile")
+		return false
+	}
+	// the lowest share allowed by the quotas limits the replicas, as in applyQuotas
+	var allowed *int32
+	for _, quota := range quotas.Items {
+		for _, constraint := range quota.Status.Constrained {
+			if constraint.Name == eifaReplica.Name && (allowed == nil || constraint.Allowed < *allowed) {
+				share := constraint.Allowed
+				allowed = &share
+			}
+		}
+	}
+	allocation := eifaReplica.Status.Allocation
+	if allowed == nil || allocation == nil {
+		return (allowed == nil) != (allocation == nil)
+	}
+	return *allowed != allocation.Allocated
+}
+
+// reallocation returns the replicas to write once the allocation of eifaReplica changed, from current. The
+// desired replicas are scheduled again, a ramp in progress keeps its step until the next one is due and
+// is only cut short when the allocation drops below current.
+func reallocation(eifaReplica *schedulev1.EifaReplica, current int32, now time.Time) int32 {
+	if eifaReplica.Status.Ramp != nil {
+		return current
+	}
+	return scheduleChange(eifaReplica, current, *eifaReplica.Status.DesiredReplicas, now)
+}
+
+// eifaReplicasOfQuota maps an EifaReplicaQuota to the EifaReplicas of its namespace
+func (r *EifaReplicaReconciler) eifaReplicasOfQuota(ctx context.Context, quota client.Object) []reconcile.Request {
+	eifaReplicas := &schedulev1.EifaReplicaList{}
+	if err := r.List(ctx, eifaReplicas, client.InNamespace(quota.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list EifaReplicas of EifaReplicaQuota", "quota", quota.GetName())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(eifaReplicas.Items))
+	for _, eifaReplica := range eifaReplicas.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&eifaReplica)})
+	}
+	return requests
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Quota", func() {
+	var quota *schedulev1.EifaReplicaQuota
+	var requests corev1.ResourceList
+
+	BeforeEach(func() {
+		maxReplicas := int32(30)
+		maxCPU := resource.MustParse("10")
+		quota = &schedulev1.EifaReplicaQuota{Spec: schedulev1.EifaReplicaQuotaSpec{
+			MaxReplicas: &maxReplicas,
+			MaxCPU:      &maxCPU,
+			Action:      schedulev1.QUOTA_CLAMP,
+		}}
+		quota.Name = "team"
+		requests = podRequests(&corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}}},
+			{Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("300m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			}}},
+		}}})
+	})
+
+	It("should sum the requests of the containers", func() {
+		Expect(requests.Cpu().MilliValue()).To(Equal(int64(500)))
+		Expect(requests.Memory().Value()).To(Equal(int64(1 << 30)))
+		Expect(podRequests(nil)).To(BeEmpty())
+
+		var used quotaUsage
+		used.add(12, requests)
+		Expect(used.cpu.MilliValue()).To(Equal(int64(6000)))
+	})
+
+	It("should clamp scale ups to the rest of the budget", func() {
+		demands := []quotaDemand{
+			{name: "api", current: 12, desired: 12, requests: requests},
+			{name: "web", current: 2, desired: 20, requests: requests},
+		}
+		// 16 replicas are left but only 8 pods fit in the 4 cpus left
+		Expect(allocate(quota, demands)).To(Equal(map[string]int32{"api": 12, "web": 8}))
+
+		quota.Spec.MaxCPU = nil
+		Expect(allocate(quota, demands)).To(Equal(map[string]int32{"api": 12, "web": 18}))
+	})
+
+	It("should not trim the replicas of the same priority", func() {
+		demands := []quotaDemand{
+			{name: "api", current: 20, desired: 25},
+			{name: "web", current: 20, desired: 10},
+			{name: "worker", current: 5, desired: 10},
+		}
+		Expect(allocate(quota, demands)).To(Equal(map[string]int32{"api": 20, "web": 10, "worker": 5}))
+	})
+
+	It("should serve the higher priorities first", func() {
+		demands := []quotaDemand{
+			{name: "batch", priority: -1, current: 10, desired: 10},
+			{name: "checkout", priority: 100, current: 10, desired: 25},
+			{name: "web", current: 5, desired: 5},
+		}
+		allocation := allocate(quota, demands)
+		Expect(allocation).To(Equal(map[string]int32{"batch": 0, "checkout": 25, "web": 5}))
+		Expect(explainAllocation(quota, demands, allocation, "batch")).To(Equal(
+			"EifaReplicaQuota team allows 0 of 10 replicas after serving checkout(priority 100, 25 replicas), web(priority 0, 5 replicas)"))
+
+		// the arbitration does not depend on the order of the demands
+		Expect(allocate(quota, []quotaDemand{demands[2], demands[0], demands[1]})).To(Equal(allocation))
+	})
+
+	It("should defer the whole scale up", func() {
+		quota.Spec.Action = schedulev1.QUOTA_DEFER
+		demands := []quotaDemand{
+			{name: "api", current: 25, desired: 25},
+			{name: "web", current: 2, desired: 10},
+		}
+		Expect(allocate(quota, demands)).To(Equal(map[string]int32{"api": 25, "web": 2}))
+
+		demands[1].desired = 5
+		Expect(allocate(quota, demands)).To(Equal(map[string]int32{"api": 25, "web": 5}))
+	})
+
+	It("should arbitrate an EifaReplica in Shadow mode without recording its share", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(schedulev1.AddToScheme(scheme)).To(Succeed())
+
+		quota.Namespace = "default"
+		replicas := int32(2)
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Replicas: &replicas, Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{Requests: requests}}},
+			}}},
+		}
+		er := &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: schedulev1.EifaReplicaSpec{
+				Mode:           schedulev1.MODE_SHADOW,
+				ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "Deployment", Name: "web"},
+			},
+		}
+		reconciler := &EifaReplicaReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(quota, deployment, er).WithStatusSubresource(quota).Build(),
+			Scheme: scheme,
+		}
+		target, err := reconciler.getScaleTarget(ctx, er)
+		Expect(err).NotTo(HaveOccurred())
+
+		// only 20 pods fit in the 10 cpus of the budget
+		allowed, limiting, err := reconciler.applyQuotas(ctx, er, target, 2, 40)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowed).To(Equal(int32(20)))
+		Expect(limiting).NotTo(BeNil())
+		Expect(er.Status.Allocation.Allocated).To(Equal(int32(20)))
+		recorded := &schedulev1.EifaReplicaQuota{}
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(quota), recorded)).To(Succeed())
+		Expect(recorded.Status.Constrained).To(BeEmpty())
+
+		er.Spec.Mode = schedulev1.MODE_ACTIVE
+		_, _, err = reconciler.applyQuotas(ctx, er, target, 2, 40)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(quota), recorded)).To(Succeed())
+		Expect(recorded.Status.Constrained).To(HaveLen(1))
+	})
+
+	Context("when another EifaReplica arbitrates the budget", func() {
+		ctx := context.Background()
+		now := time.Now()
+		var er *schedulev1.EifaReplica
+		var reconciler *EifaReplicaReconciler
+
+		// constrain records the arbitration of the budget made by another EifaReplica, allowing web the replicas
+		constrain := func(allowed ...int32) {
+			quota.Namespace = "default"
+			quota.Status.Constrained = nil
+			for _, replicas := range allowed {
+				quota.Status.Constrained = append(quota.Status.Constrained, schedulev1.QuotaConstraint{Name: "web", Desired: 20, Allowed: replicas})
+			}
+			scheme := runtime.NewScheme()
+			Expect(schedulev1.AddToScheme(scheme)).To(Succeed())
+			reconciler = &EifaReplicaReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(quota).Build(), Scheme: scheme}
+		}
+
+		BeforeEach(func() {
+			desired := int32(20)
+			er = &schedulev1.EifaReplica{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+			er.Status.DesiredReplicas = &desired
+			er.Status.Allocation = &schedulev1.QuotaAllocation{Quota: "team", Desired: 20, Allocated: 12}
+		})
+
+		It("should reallocate on any change of the allowed share", func() {
+			constrain(12)
+			Expect(reconciler.allocationDue(ctx, er)).To(BeFalse())
+			constrain(8)
+			Expect(reconciler.allocationDue(ctx, er)).To(BeTrue())
+			constrain(16)
+			Expect(reconciler.allocationDue(ctx, er)).To(BeTrue())
+
+			// the budget freed up for the whole scale up
+			constrain()
+			Expect(reconciler.allocationDue(ctx, er)).To(BeTrue())
+		})
+
+		It("should not advance a ramp before its next step", func() {
+			next := metav1.NewTime(now.Add(time.Minute))
+			er.Status.Allocation = nil
+			er.Status.Ramp = &schedulev1.RampStatus{From: 4, To: 20, Steps: 4, Step: 1, StartTime: metav1.NewTime(now), NextStepTime: &next}
+			constrain(6)
+			Expect(reconciler.allocationDue(ctx, er)).To(BeTrue())
+			Expect(rampDue(er, now)).To(BeFalse())
+
+			Expect(reallocation(er, 8, now)).To(Equal(int32(8)))
+			Expect(er.Status.Ramp.Step).To(Equal(int32(1)))
+			Expect(er.Status.Ramp.NextStepTime.Time).To(Equal(next.Time))
+		})
+	})
+})
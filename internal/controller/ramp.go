@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"time"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// planRamp replaces the ramp in progress with a ramp from current to desired and returns
+// the replicas of its first step, desired is returned as is when the change is not ramped.
+// The first step is taken once it is written, like every step.
+func planRamp(eifaReplica *schedulev1.EifaReplica, current, desired int32, now time.Time) int32 {
+	eifaReplica.Status.Ramp = nil
+
+	var ramp *schedulev1.RampConfig
+	if eifaReplica.Spec.Behavior != nil {
+		ramp = eifaReplica.Spec.Behavior.Ramp
+	}
+	change := desired - current
+	if ramp == nil || ramp.Steps < 2 || change == 0 || max(change, -change) < ramp.MinReplicaChange {
+		return desired
+	}
+
+	eifaReplica.Status.Ramp = &schedulev1.RampStatus{
+		From:      current,
+		To:        desired,
+		Steps:     ramp.Steps,
+		StartTime: metav1.NewTime(now),
+	}
+	return rampStep(eifaReplica)
+}
+
+// rampDue reports whether the next step of the ramp in progress must be written, the first step
+// of a ramp is due until it is written
+func rampDue(eifaReplica *schedulev1.EifaReplica, now time.Time) bool {
+	ramp := eifaReplica.Status.Ramp
+	if ramp == nil {
+		return false
+	}
+	if ramp.NextStepTime == nil {
+		return ramp.Step == 0
+	}
+	return !now.Before(ramp.NextStepTime.Time)
+}
+
+// rampStep returns the replicas of the next step of the ramp in progress
+func rampStep(eifaReplica *schedulev1.EifaReplica) int32 {
+	ramp := eifaReplica.Status.Ramp
+	step := min(ramp.Step+1, ramp.Steps)
+	return ramp.From + (ramp.To-ramp.From)*step/ramp.Steps
+}
+
+// advanceRamp moves the ramp in progress to its next step once it is written and returns the replicas
+// of that step, a refused write leaves the step due
+func advanceRamp(eifaReplica *schedulev1.EifaReplica) int32 {
+	replicas := rampStep(eifaReplica)
+	ramp := eifaReplica.Status.Ramp
+	ramp.Step = min(ramp.Step+1, ramp.Steps)
+
+	ramp.NextStepTime = nil
+	if ramp.Step < ramp.Steps {
+		// the steps are spread evenly, so the last one is written after the ramp duration
+		var duration time.Duration
+		if eifaReplica.Spec.Behavior != nil && eifaReplica.Spec.Behavior.Ramp != nil {
+			duration = time.Duration(eifaReplica.Spec.Behavior.Ramp.DurationSeconds) * time.Second
+		}
+		next := metav1.NewTime(ramp.StartTime.Add(duration * time.Duration(ramp.Step) / time.Duration(ramp.Steps-1)))
+		ramp.NextStepTime = &next
+	}
+	return replicas
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Ramp", func() {
+	var er *schedulev1.EifaReplica
+	now := time.Now()
+
+	BeforeEach(func() {
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			Behavior: &schedulev1.EifaReplicaBehavior{
+				Ramp: &schedulev1.RampConfig{Steps: 5, DurationSeconds: 400, MinReplicaChange: 10},
+			},
+		}}
+	})
+
+	It("should write small changes at once", func() {
+		Expect(planRamp(er, 5, 10, now)).To(Equal(int32(10)))
+		Expect(er.Status.Ramp).To(BeNil())
+	})
+
+	It("should spread a large change over the ramp duration", func() {
+		Expect(planRamp(er, 5, 80, now)).To(Equal(int32(20)))
+		Expect(er.Status.Ramp.Step).To(BeZero())
+		Expect(rampDue(er, now)).To(BeTrue())
+		Expect(advanceRamp(er)).To(Equal(int32(20)))
+		Expect(er.Status.Ramp.Step).To(Equal(int32(1)))
+		Expect(rampDue(er, now)).To(BeFalse())
+		Expect(rampDue(er, now.Add(100*time.Second))).To(BeTrue())
+
+		steps := []int32{}
+		for rampDue(er, now.Add(time.Hour)) {
+			steps = append(steps, advanceRamp(er))
+		}
+		Expect(steps).To(Equal([]int32{35, 50, 65, 80}))
+		Expect(er.Status.Ramp.NextStepTime).To(BeNil())
+	})
+
+	It("should keep a step due until it is written", func() {
+		planRamp(er, 5, 80, now)
+		advanceRamp(er)
+
+		// the write of the second step is refused
+		Expect(rampStep(er)).To(Equal(int32(35)))
+		Expect(rampDue(er, now.Add(time.Hour))).To(BeTrue())
+
+		// and retried later
+		Expect(rampStep(er)).To(Equal(int32(35)))
+		Expect(advanceRamp(er)).To(Equal(int32(35)))
+		Expect(er.Status.Ramp.Step).To(Equal(int32(2)))
+	})
+
+	It("should re-plan the ramp on a new job result", func() {
+		planRamp(er, 5, 80, now)
+		Expect(planRamp(er, 20, 15, now.Add(time.Minute))).To(Equal(int32(15)))
+		Expect(er.Status.Ramp).To(BeNil())
+
+		Expect(planRamp(er, 20, 0, now.Add(time.Minute))).To(Equal(int32(16)))
+		Expect(er.Status.Ramp.From).To(Equal(int32(20)))
+	})
+})
@@ -7,6 +7,7 @@ import (
 
 	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
 	"github.com/gorhill/cronexpr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -23,6 +24,19 @@ func (r *EifaReplicaReconciler) GetDesiredReplica(ctx context.Context, req ctrl.
 		// set next time base on NextTransitionTime
 		next = t
 
+		// the result of a job run ahead of its scheduled time is applied once the time is reached
+		if desiredReplica, ok := releaseResult(eifaReplica, time.Now()); ok {
+			return &desiredReplica, &next, nil
+		}
+
+		// the points of the timeline printed by the last job are applied at their time without running it
+		if output, ok, err := r.timelineDue(ctx, eifaReplica, time.Now()); err != nil {
+			return nil, &next, fmt.Errorf("[apply-timeline] %s", err)
+		} else if ok {
+			desiredReplica, err := acceptOutput(eifaReplica, output)
+			return desiredReplica, &next, err
+		}
+
 		if time.Now().Before(next) {
 			return nil, &next, nil
 		}
@@ -35,13 +49,116 @@ func (r *EifaReplicaReconciler) GetDesiredReplica(ctx context.Context, req ctrl.
 	}
 
 	// run job
-	desiredReplica, err := r.runJob(ctx, req, eifaReplica)
-	next = cron.Next(time.Now())
+	start := time.Now()
+	result, err := r.runJob(ctx, req, eifaReplica)
+	next = nextRun(eifaReplica, cron, time.Now())
+	recordAttempt(eifaReplica, next)
 
 	// job failed
 	if err != nil {
+		if retry, ok := retryTime(eifaReplica, time.Now(), next); ok {
+			next = retry
+			return nil, &next, fmt.Errorf("[run-job] %s, retry %d/%d at %s", err,
+				eifaReplica.Status.Attempts, eifaReplica.Spec.RetryPolicy.MaxRetries, retry.Format(time.RFC3339))
+		}
+		eifaReplica.Status.ConsecutiveFailures++
+		if until, open := r.openCircuit(eifaReplica, time.Now()); open {
+			// the first slot after the open interval runs the probe job
+			next = nextRun(eifaReplica, cron, until)
+			return nil, &next, fmt.Errorf("[run-job] %s, circuit open until %s", err, until.Format(time.RFC3339))
+		}
 		return nil, &next, fmt.Errorf("[run-job] %s", err)
 	}
-	return &desiredReplica, &next, nil
+	eifaReplica.Status.ConsecutiveFailures = 0
+	r.closeCircuit(eifaReplica, time.Now())
+
+	if result.Timeline != nil {
+		// a new timeline replaces the previous one, its first points may already be due
+		if err := r.storeTimeline(ctx, eifaReplica, result.Timeline); err != nil {
+			return nil, &next, fmt.Errorf("[store-timeline] %s", err)
+		}
+		recordRun(eifaReplica, nil)
+		eifaReplica.Status.PendingResult = nil
+		output, ok := nextPoint(eifaReplica.Status.Timeline, result.Timeline, time.Now())
+		if !ok {
+			return nil, &next, nil
+		}
+		desiredReplica, err := acceptOutput(eifaReplica, output)
+		return desiredReplica, &next, err
+	}
+	if err := r.dropTimeline(ctx, eifaReplica); err != nil {
+		return nil, &next, fmt.Errorf("[drop-timeline] %s", err)
+	}
 
+	desiredReplica, err := acceptOutput(eifaReplica, result.Replicas)
+	if err != nil {
+		return nil, &next, err
+	}
+	recordRun(eifaReplica, result.TTLSeconds)
+
+	if holdResult(eifaReplica, cron, *desiredReplica, start, time.Now()) {
+		return nil, &next, nil
+	}
+	return desiredReplica, &next, nil
+
+}
+
+// acceptOutput checks a job output and returns it clamped to the min and max replicas
+func acceptOutput(eifaReplica *schedulev1.EifaReplica, output int32) (*int32, error) {
+	if err := checkOutput(eifaReplica, output); err != nil {
+		return nil, err
+	}
+	desiredReplica := max(eifaReplica.Spec.MinReplicas, min(eifaReplica.Spec.MaxReplicas, output))
+	// record the result, it is persisted with the next status update
+	eifaReplica.Status.LastJobOutput = &output
+	return &desiredReplica, nil
+}
+
+// recordRun records a successful job run whose result lives for ttlSeconds
+func recordRun(eifaReplica *schedulev1.EifaReplica, ttlSeconds *int32) {
+	now := metav1.Now()
+	eifaReplica.Status.LastSuccessfulRunTime = &now
+	eifaReplica.Status.ResultTTLSeconds = ttlSeconds
+	eifaReplica.Status.Stale = false
+}
+
+// pendingRequeue shortens requeueAfter to the next ramp step, to the end of the cooldown deferring
+// the desired replicas, to the scheduled time of a held result, to the next point of the timeline
+// or to the time the last job result becomes stale
+func pendingRequeue(eifaReplica *schedulev1.EifaReplica, requeueAfter time.Duration) time.Duration {
+	if ramp := eifaReplica.Status.Ramp; ramp != nil && ramp.NextStepTime != nil {
+		requeueAfter = min(requeueAfter, time.Until(ramp.NextStepTime.Time))
+	}
+	if deferredUntil := eifaReplica.Status.DeferredUntil; deferredUntil != nil {
+		requeueAfter = min(requeueAfter, time.Until(deferredUntil.Time))
+	}
+	if verification := eifaReplica.Status.Verification; verification != nil {
+		requeueAfter = min(requeueAfter, time.Until(verification.Deadline.Time), verifyPollInterval)
+	}
+	if prewarm := eifaReplica.Status.Prewarm; prewarm != nil {
+		requeueAfter = min(requeueAfter, time.Until(prewarm.ApplyTime.Add(prewarmLead(eifaReplica))), verifyPollInterval)
+	}
+	if eifaReplica.Spec.Prewarm != nil {
+		// start the placeholders of the upcoming scale up a lead time ahead of it
+		lead := prewarmLead(eifaReplica)
+		if pending := eifaReplica.Status.PendingResult; pending != nil && time.Until(pending.EffectiveTime.Add(-lead)) > 0 {
+			requeueAfter = min(requeueAfter, time.Until(pending.EffectiveTime.Add(-lead)))
+		}
+		if timeline := eifaReplica.Status.Timeline; timeline != nil && timeline.NextTime != nil && time.Until(timeline.NextTime.Add(-lead)) > 0 {
+			requeueAfter = min(requeueAfter, time.Until(timeline.NextTime.Add(-lead)))
+		}
+	}
+	if timeline := eifaReplica.Status.Timeline; timeline != nil && timeline.NextTime != nil {
+		requeueAfter = min(requeueAfter, time.Until(timeline.NextTime.Time))
+	}
+	if pending := eifaReplica.Status.PendingResult; pending != nil {
+		requeueAfter = min(requeueAfter, time.Until(pending.EffectiveTime.Time))
+	}
+	if proposal := eifaReplica.Status.PendingApproval; proposal != nil {
+		requeueAfter = min(requeueAfter, time.Until(proposal.ExpiryTime.Time))
+	}
+	if expiry, ok := resultExpiry(eifaReplica); ok && !eifaReplica.Status.Stale {
+		requeueAfter = min(requeueAfter, time.Until(expiry))
+	}
+	return max(time.Second, requeueAfter)
 }
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// podUsage returns the resources a pod of template consumes from a ResourceQuota
+func podUsage(template *corev1.PodTemplateSpec) corev1.ResourceList {
+	usage := corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")}
+	add := func(name corev1.ResourceName, quantity resource.Quantity) {
+		sum := usage[name]
+		sum.Add(quantity)
+		usage[name] = sum
+	}
+	for _, container := range template.Spec.Containers {
+		if quantity, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+			add(corev1.ResourceRequestsCPU, quantity)
+			add(corev1.ResourceCPU, quantity)
+		}
+		if quantity, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+			add(corev1.ResourceRequestsMemory, quantity)
+			add(corev1.ResourceMemory, quantity)
+		}
+		if quantity, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+			add(corev1.ResourceLimitsCPU, quantity)
+		}
+		if quantity, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+			add(corev1.ResourceLimitsMemory, quantity)
+		}
+	}
+	return usage
+}
+
+// resourceQuotaFit returns how many more pods consuming usage fit in quota and the resource limiting them,
+// math.MaxInt32 when quota does not limit them
+func resourceQuotaFit(quota *corev1.ResourceQuota, usage corev1.ResourceList) (int64, corev1.ResourceName) {
+	fit, limiting := int64(math.MaxInt32), corev1.ResourceName("")
+	for name, perPod := range usage {
+		hard, ok := quota.Status.Hard[name]
+		if !ok {
+			hard, ok = quota.Spec.Hard[name]
+		}
+		if !ok || perPod.IsZero() {
+			continue
+		}
+		used := quota.Status.Used[name]
+		left := max(0, hard.MilliValue()-used.MilliValue())
+		// the resource name breaks ties, so the limiting resource is stable across reconciles
+		if pods := left / perPod.MilliValue(); pods < fit || (pods == fit && limiting != "" && name < limiting) {
+			fit, limiting = pods, name
+		}
+	}
+	return fit, limiting
+}
+
+// resourceQuotaShortfall describes how much of the resource name quota misses for pods additional pods consuming usage
+func resourceQuotaShortfall(quota *corev1.ResourceQuota, usage corev1.ResourceList, name corev1.ResourceName, pods int32) string {
+	hard, ok := quota.Status.Hard[name]
+	if !ok {
+		hard = quota.Spec.Hard[name]
+	}
+	left := hard.DeepCopy()
+	left.Sub(quota.Status.Used[name])
+	if left.Sign() < 0 {
+		left = resource.Quantity{Format: left.Format}
+	}
+	needed := usage[name].DeepCopy()
+	needed.Mul(int64(pods))
+	short := needed.DeepCopy()
+	short.Sub(left)
+	return fmt.Sprintf("%s short by %s (%s needed, %s left)", name, short.String(), needed.String(), left.String())
+}
+
+// scopedQuota reports whether quota only tracks the pods of some scopes, such quotas are not evaluated
+func scopedQuota(quota *corev1.ResourceQuota) bool {
+	return len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil
+}
+
+// applyResourceQuotas clamps a scale up of eifaReplica from current to desired to the pods fitting in the
+// ResourceQuotas of its namespace, it returns the replicas to write and the shortfall, empty when desired fits.
+// The new pods are counted against the replicas of the deployment behind the target, which the min replicas
+// of an autoscaler only raise when they exceed them. Quotas with scopes are not evaluated, and raising the max
+// replicas of an autoscaler creates no pod.
+func (r *EifaReplicaReconciler) applyResourceQuotas(ctx context.Context, eifaReplica *schedulev1.EifaReplica, target scaleTarget, current, desired int32) (int32, string, error) {
+	autoscalerMax := normalizeKind(eifaReplica.Spec.ScaleTargetRef.Kind) != kindDeployment &&
+		targetMode(eifaReplica) == schedulev1.MODE_MAX_REPLICAS
+	if desired <= current || autoscalerMax {
+		return desired, "", nil
+	}
+	quotas := &corev1.ResourceQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(eifaReplica.Namespace)); err != nil {
+		return desired, "", fmt.Errorf("can not list resourcequotas, %s", err)
+	}
+	if len(quotas.Items) == 0 {
+		return desired, "", nil
+	}
+	deployment, err := r.workload(ctx, eifaReplica.Namespace, target)
+	if err != nil {
+		return desired, "", fmt.Errorf("can not get the deployment of the target, %s", err)
+	}
+	if deployment == nil {
+		return desired, "", nil
+	}
+	running := int32(1)
+	if deployment.Spec.Replicas != nil {
+		running = *deployment.Spec.Replicas
+	}
+	if desired <= running {
+		return desired, "", nil
+	}
+
+	usage := podUsage(&deployment.Spec.Template)
+	// the placeholders of a pre-warmed scale up hold the share of its pods
+	var placeholders int64
+	if prewarm := eifaReplica.Status.Prewarm; prewarm != nil {
+		placeholders = int64(prewarm.Placeholders)
+	}
+	allowed, shortfall := desired, ""
+	for i := range quotas.Items {
+		quota := &quotas.Items[i]
+		if scopedQuota(quota) {
+			continue
+		}
+		fit, name := resourceQuotaFit(quota, usage)
+		if replicas := int32(min(int64(running)+fit+placeholders, math.MaxInt32)); replicas < allowed {
+			allowed = max(current, replicas)
+			shortfall = fmt.Sprintf("ResourceQuota %s %s", quota.Name,
+				resourceQuotaShortfall(quota, usage, name, desired-running))
+		}
+	}
+	return allowed, shortfall, nil
+}
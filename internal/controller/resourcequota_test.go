@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("ResourceQuota", func() {
+	var usage corev1.ResourceList
+
+	BeforeEach(func() {
+		usage = podUsage(&corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("256Mi"),
+				},
+				Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			},
+		}}}})
+	})
+
+	It("should count the pod the way the quota does", func() {
+		Expect(usage.Pods().Value()).To(Equal(int64(1)))
+		Expect(usage.Name(corev1.ResourceRequestsCPU, resource.DecimalSI).MilliValue()).To(Equal(int64(500)))
+		Expect(usage.Name(corev1.ResourceCPU, resource.DecimalSI).MilliValue()).To(Equal(int64(500)))
+		Expect(usage.Name(corev1.ResourceLimitsCPU, resource.DecimalSI).MilliValue()).To(Equal(int64(1000)))
+		Expect(usage).NotTo(HaveKey(corev1.ResourceLimitsMemory))
+	})
+
+	It("should fit the pods in the rest of the quota", func() {
+		quota := &corev1.ResourceQuota{Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("10"),
+				corev1.ResourcePods:        resource.MustParse("50"),
+			},
+			Used: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("8"),
+				corev1.ResourcePods:        resource.MustParse("16"),
+			},
+		}}
+		fit, name := resourceQuotaFit(quota, usage)
+		Expect(fit).To(Equal(int64(4)))
+		Expect(name).To(Equal(corev1.ResourceRequestsCPU))
+		Expect(resourceQuotaShortfall(quota, usage, name, 10)).To(Equal("requests.cpu short by 3 (5 needed, 2 left)"))
+
+		fit, name = resourceQuotaFit(&corev1.ResourceQuota{}, usage)
+		Expect(fit).To(Equal(int64(math.MaxInt32)))
+		Expect(name).To(BeEmpty())
+	})
+
+	It("should not fit pods in an exceeded quota", func() {
+		quota := &corev1.ResourceQuota{Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("4")},
+			Used: corev1.ResourceList{corev1.ResourceLimitsCPU: resource.MustParse("5")},
+		}}
+		fit, name := resourceQuotaFit(quota, usage)
+		Expect(fit).To(BeZero())
+		Expect(name).To(Equal(corev1.ResourceLimitsCPU))
+		Expect(resourceQuotaShortfall(quota, usage, name, 2)).To(Equal("limits.cpu short by 2 (2 needed, 0 left)"))
+	})
+
+	It("should count the new pods against the deployment behind an autoscaler", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(schedulev1.AddToScheme(scheme)).To(Succeed())
+
+		replicas := int32(8)
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+		minReplicas := int32(4)
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+				MinReplicas:    &minReplicas,
+				MaxReplicas:    20,
+			},
+		}
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "pods", Namespace: "default"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+				Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("9")},
+			},
+		}
+		er := &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       schedulev1.EifaReplicaSpec{ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "HorizontalPodAutoscaler", Name: "web"}},
+		}
+		reconciler := &EifaReplicaReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployment, hpa, quota).Build(),
+			Scheme: scheme,
+		}
+		target, err := reconciler.getScaleTarget(ctx, er)
+		Expect(err).NotTo(HaveOccurred())
+
+		// raising the min replicas up to the deployment replicas creates no pod
+		allowed, shortfall, err := reconciler.applyResourceQuotas(ctx, er, target, 4, 8)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowed).To(Equal(int32(8)))
+		Expect(shortfall).To(BeEmpty())
+
+		allowed, shortfall, err = reconciler.applyResourceQuotas(ctx, er, target, 4, 12)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowed).To(Equal(int32(9)))
+		Expect(shortfall).To(Equal("ResourceQuota pods pods short by 3 (4 needed, 1 left)"))
+	})
+})
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"math"
+	"time"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// backoff of the first retry when spec.retryPolicy.backoffSeconds is not persisted
+const defaultRetryBackoff = 10 * time.Second
+
+// recordAttempt counts a job run in the schedule slot ending at slotEnd
+func recordAttempt(eifaReplica *schedulev1.EifaReplica, slotEnd time.Time) {
+	if eifaReplica.Status.SlotEndTime == nil || !eifaReplica.Status.SlotEndTime.Time.Equal(slotEnd) {
+		slotEndTime := metav1.NewTime(slotEnd)
+		eifaReplica.Status.SlotEndTime = &slotEndTime
+		eifaReplica.Status.Attempts = 0
+	}
+	eifaReplica.Status.Attempts++
+}
+
+// retryTime returns when the failed job of the current slot is retried, the backoff doubles on every
+// attempt and false is returned when no retry is left or the retry would run past slotEnd
+func retryTime(eifaReplica *schedulev1.EifaReplica, now, slotEnd time.Time) (time.Time, bool) {
+	policy := eifaReplica.Spec.RetryPolicy
+	if policy == nil || eifaReplica.Status.Attempts > policy.MaxRetries {
+		return time.Time{}, false
+	}
+
+	backoff := defaultRetryBackoff
+	if policy.BackoffSeconds > 0 {
+		backoff = time.Duration(policy.BackoffSeconds) * time.Second
+	}
+	retry := now.Add(exponentialBackoff(backoff, policy.MaxBackoffSeconds, eifaReplica.Status.Attempts))
+	if !retry.Before(slotEnd) {
+		return time.Time{}, false
+	}
+	return retry, true
+}
+
+// exponentialBackoff returns base doubled for every attempt after the first one, capped to maxSeconds when it is set
+func exponentialBackoff(base time.Duration, maxSeconds *int32, attempt int32) time.Duration {
+	maxBackoff := time.Duration(math.MaxInt64)
+	if maxSeconds != nil {
+		maxBackoff = time.Duration(*maxSeconds) * time.Second
+	}
+	backoff := base
+	for i := int32(1); i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
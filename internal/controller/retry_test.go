@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Retry", func() {
+	var er *schedulev1.EifaReplica
+	now := time.Now()
+	slotEnd := now.Add(time.Hour)
+
+	BeforeEach(func() {
+		maxBackoff := int32(120)
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			RetryPolicy: &schedulev1.RetryPolicy{MaxRetries: 4, BackoffSeconds: 30, MaxBackoffSeconds: &maxBackoff},
+		}}
+	})
+
+	It("should count the attempts of a slot", func() {
+		recordAttempt(er, slotEnd)
+		recordAttempt(er, slotEnd)
+		Expect(er.Status.Attempts).To(Equal(int32(2)))
+
+		recordAttempt(er, slotEnd.Add(time.Hour))
+		Expect(er.Status.Attempts).To(Equal(int32(1)))
+		Expect(er.Status.SlotEndTime.Time).To(BeTemporally("==", slotEnd.Add(time.Hour)))
+	})
+
+	It("should retry with an exponential backoff", func() {
+		delays := []time.Duration{}
+		for {
+			recordAttempt(er, slotEnd)
+			retry, ok := retryTime(er, now, slotEnd)
+			if !ok {
+				break
+			}
+			delays = append(delays, retry.Sub(now))
+		}
+		Expect(delays).To(Equal([]time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 2 * time.Minute}))
+	})
+
+	It("should never retry past the next scheduled time", func() {
+		recordAttempt(er, slotEnd)
+		_, ok := retryTime(er, now, now.Add(10*time.Second))
+		Expect(ok).To(BeFalse())
+	})
+})
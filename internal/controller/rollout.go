@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// rolloutRetryInterval is the delay before retrying a change of the replicas deferred by a rollout
+const rolloutRetryInterval = 15 * time.Second
+
+// rolloutInProgress reports whether deployment is rolling out a new pod template: its controller has
+// not observed the last spec yet, or pods of an older template are still running, as with a paused canary.
+// A change of the replicas alone creates no pod of an older template.
+func rolloutInProgress(deployment *appsv1.Deployment) bool {
+	return deployment.Status.ObservedGeneration < deployment.Generation ||
+		deployment.Status.UpdatedReplicas < deployment.Status.Replicas
+}
+
+// waitingForRollout returns the deployment behind target when eifaReplica waits for its rollout to complete
+func (r *EifaReplicaReconciler) waitingForRollout(ctx context.Context, eifaReplica *schedulev1.EifaReplica, target scaleTarget) (*appsv1.Deployment, error) {
+	if !eifaReplica.Spec.WaitForRollout {
+		return nil, nil
+	}
+	deployment, err := r.workload(ctx, eifaReplica.Namespace, target)
+	if err != nil || deployment == nil || !rolloutInProgress(deployment) {
+		return nil, err
+	}
+	return deployment, nil
+}
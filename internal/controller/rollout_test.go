@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Rollout", func() {
+	var deployment *appsv1.Deployment
+
+	BeforeEach(func() {
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 3},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 10, UpdatedReplicas: 10},
+		}
+	})
+
+	It("should detect a rollout in progress", func() {
+		Expect(rolloutInProgress(deployment)).To(BeFalse())
+
+		deployment.Status.UpdatedReplicas = 2
+		Expect(rolloutInProgress(deployment)).To(BeTrue())
+
+		deployment.Status.UpdatedReplicas = 10
+		deployment.Generation = 4
+		Expect(rolloutInProgress(deployment)).To(BeTrue())
+	})
+
+	It("should not detect a change of the replicas alone", func() {
+		// the new pods of a scale up run the current template
+		deployment.Status.Replicas, deployment.Status.UpdatedReplicas = 14, 14
+		deployment.Status.AvailableReplicas = 10
+		Expect(rolloutInProgress(deployment)).To(BeFalse())
+	})
+
+	It("should only wait when asked to", func() {
+		deployment.Status.UpdatedReplicas = 2
+		er := &schedulev1.EifaReplica{}
+		r := &EifaReplicaReconciler{}
+		target := &deploymentTarget{deployment: deployment}
+
+		waiting, err := r.waitingForRollout(context.Background(), er, target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(waiting).To(BeNil())
+
+		er.Spec.WaitForRollout = true
+		waiting, err = r.waitingForRollout(context.Background(), er, target)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(waiting).To(Equal(deployment))
+	})
+})
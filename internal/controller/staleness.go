@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"time"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// resultExpiry returns when the last successful job result becomes stale, the earliest of
+// spec.maxResultAge and the time to live reported by the job, false when it never does.
+// The age of a timeline counts from its last point.
+func resultExpiry(eifaReplica *schedulev1.EifaReplica) (time.Time, bool) {
+	if eifaReplica.Status.LastSuccessfulRunTime == nil {
+		return time.Time{}, false
+	}
+	var ttl time.Duration
+	if eifaReplica.Spec.MaxResultAge != nil {
+		ttl = eifaReplica.Spec.MaxResultAge.Duration
+	}
+	if eifaReplica.Status.ResultTTLSeconds != nil {
+		resultTTL := time.Duration(*eifaReplica.Status.ResultTTLSeconds) * time.Second
+		if ttl == 0 || resultTTL < ttl {
+			ttl = resultTTL
+		}
+	}
+	if ttl <= 0 {
+		return time.Time{}, false
+	}
+	if timeline := eifaReplica.Status.Timeline; timeline != nil {
+		// a timeline is fresh until its last point
+		if timeline.NextTime != nil {
+			return time.Time{}, false
+		}
+		return timeline.EndTime.Add(ttl), true
+	}
+	return eifaReplica.Status.LastSuccessfulRunTime.Add(ttl), true
+}
+
+// staleDue reports whether the last job result went stale and the target was not reverted to the baseline yet
+func staleDue(eifaReplica *schedulev1.EifaReplica, now time.Time) bool {
+	expiry, ok := resultExpiry(eifaReplica)
+	return ok && !eifaReplica.Status.Stale && !now.Before(expiry)
+}
+
+// revertToBaseline marks the last job result stale and returns the baseline replicas,
+// any ramp or deferred change of the stale result is cancelled
+func revertToBaseline(eifaReplica *schedulev1.EifaReplica) int32 {
+	baseline := eifaReplica.Spec.MinReplicas
+	if eifaReplica.Spec.BaselineReplicas != nil {
+		baseline = max(eifaReplica.Spec.MinReplicas, min(eifaReplica.Spec.MaxReplicas, *eifaReplica.Spec.BaselineReplicas))
+	}
+
+	eifaReplica.Status.Stale = true
+	eifaReplica.Status.Ramp = nil
+	eifaReplica.Status.DeferredUntil = nil
+	eifaReplica.Status.DesiredReplicas = &baseline
+	return baseline
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Staleness", func() {
+	var er *schedulev1.EifaReplica
+	now := time.Now()
+
+	BeforeEach(func() {
+		baseline := int32(6)
+		er = &schedulev1.EifaReplica{Spec: schedulev1.EifaReplicaSpec{
+			MinReplicas:      2,
+			MaxReplicas:      10,
+			MaxResultAge:     &metav1.Duration{Duration: 2 * time.Hour},
+			BaselineReplicas: &baseline,
+		}}
+		er.Status.LastSuccessfulRunTime = &metav1.Time{Time: now}
+	})
+
+	It("should parse the job output", func() {
+		result, err := parseJobOutput("7\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Replicas).To(Equal(int32(7)))
+		Expect(result.TTLSeconds).To(BeNil())
+
+		result, err = parseJobOutput(`{"replicas": 12, "ttlSeconds": 600}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Replicas).To(Equal(int32(12)))
+		Expect(*result.TTLSeconds).To(Equal(int32(600)))
+
+		_, err = parseJobOutput(`{"replicas": 12, "ttlSeconds": 0}`)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should expire the result after the earliest of max result age and ttl", func() {
+		Expect(staleDue(er, now.Add(time.Hour))).To(BeFalse())
+		Expect(staleDue(er, now.Add(2*time.Hour))).To(BeTrue())
+
+		ttl := int32(600)
+		er.Status.ResultTTLSeconds = &ttl
+		expiry, ok := resultExpiry(er)
+		Expect(ok).To(BeTrue())
+		Expect(expiry).To(BeTemporally("==", now.Add(10*time.Minute)))
+
+		er.Spec.MaxResultAge = nil
+		er.Status.ResultTTLSeconds = nil
+		_, ok = resultExpiry(er)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should revert to the baseline once", func() {
+		Expect(revertToBaseline(er)).To(Equal(int32(6)))
+		Expect(*er.Status.DesiredReplicas).To(Equal(int32(6)))
+		Expect(staleDue(er, now.Add(3*time.Hour))).To(BeFalse())
+
+		er.Spec.BaselineReplicas = nil
+		Expect(revertToBaseline(er)).To(Equal(int32(2)))
+	})
+})
@@ -9,18 +9,9 @@ import (
 )
 
 func (r *EifaReplicaReconciler) UpdateStatus(ctx context.Context, eifaReplica *schedulev1.EifaReplica, cond *metav1.Condition, next *time.Time) error {
-	if cond == nil && next == nil {
-		// nothing to do
-		return nil
-	}
+	// always persist, the scaling state may have changed without a condition or a job run
 	if cond != nil {
-		// append
-		eifaReplica.Status.Conditions = append(eifaReplica.Status.Conditions, *cond)
-
-		// store only last 10 conditions
-		if len(eifaReplica.Status.Conditions) > 10 {
-			eifaReplica.Status.Conditions = eifaReplica.Status.Conditions[len(eifaReplica.Status.Conditions)-10:]
-		}
+		appendCondition(eifaReplica, *cond)
 	}
 
 	if next != nil {
@@ -28,3 +19,13 @@ func (r *EifaReplicaReconciler) UpdateStatus(ctx context.Context, eifaReplica *s
 	}
 	return r.Status().Update(ctx, eifaReplica)
 }
+
+// appendCondition appends cond to the status without persisting it
+func appendCondition(eifaReplica *schedulev1.EifaReplica, cond metav1.Condition) {
+	eifaReplica.Status.Conditions = append(eifaReplica.Status.Conditions, cond)
+
+	// store only last 10 conditions
+	if len(eifaReplica.Status.Conditions) > 10 {
+		eifaReplica.Status.Conditions = eifaReplica.Status.Conditions[len(eifaReplica.Status.Conditions)-10:]
+	}
+}
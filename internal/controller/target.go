@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+const (
+	kindDeployment              = "deployment"
+	kindHorizontalPodAutoscaler = "horizontalpodautoscaler"
+	kindScaledObject            = "scaledobject"
+)
+
+// scaledObjectGVK is accessed through unstructured objects, so KEDA is not a build dependency
+var scaledObjectGVK = schema.GroupVersionKind{Group: "keda.sh", Version: "v1alpha1", Kind: "ScaledObject"}
+
+// KEDA defaults of the ScaledObject bounds
+const (
+	defaultScaledObjectMinReplicaCount = int64(0)
+	defaultScaledObjectMaxReplicaCount = int64(100)
+)
+
+// normalizeKind maps the accepted spellings of .Spec.ScaleTargetRef.Kind to a single lower case kind
+func normalizeKind(kind string) string {
+	kind = strings.ToLower(kind)
+	switch kind {
+	case "deploy":
+		return kindDeployment
+	case "hpa":
+		return kindHorizontalPodAutoscaler
+	}
+	return kind
+}
+
+// scaleTarget is the object whose replicas are driven by an EifaReplica
+type scaleTarget interface {
+	// Object returns the underlying object to be updated
+	Object() client.Object
+	// Replicas returns the replica count currently driven by the EifaReplica
+	Replicas() int32
+	// SetReplicas sets the driven replica count, it returns the value actually set
+	// which may differ from replicas when the target has its own bounds
+	SetReplicas(replicas int32) int32
+}
+
+type deploymentTarget struct {
+	deployment *appsv1.Deployment
+}
+
+func (t *deploymentTarget) Object() client.Object {
+	return t.deployment
+}
+
+func (t *deploymentTarget) Replicas() int32 {
+	if t.deployment.Spec.Replicas == nil {
+		// the api server defaults missing replicas to 1
+		return 1
+	}
+	return *t.deployment.Spec.Replicas
+}
+
+func (t *deploymentTarget) SetReplicas(replicas int32) int32 {
+	t.deployment.Spec.Replicas = &replicas
+	return replicas
+}
+
+// hpaTarget drives one of the bounds of a HorizontalPodAutoscaler instead of the replicas of a workload
+type hpaTarget struct {
+	hpa  *autoscalingv2.HorizontalPodAutoscaler
+	mode string
+}
+
+func (t *hpaTarget) Object() client.Object {
+	return t.hpa
+}
+
+func (t *hpaTarget) Replicas() int32 {
+	if t.mode == schedulev1.MODE_MAX_REPLICAS {
+		return t.hpa.Spec.MaxReplicas
+	}
+	if t.hpa.Spec.MinReplicas == nil {
+		return 1
+	}
+	return *t.hpa.Spec.MinReplicas
+}
+
+func (t *hpaTarget) SetReplicas(replicas int32) int32 {
+	// an HPA requires 1 <= minReplicas <= maxReplicas
+	replicas = max(1, replicas)
+	if t.mode == schedulev1.MODE_MAX_REPLICAS {
+		if t.hpa.Spec.MinReplicas != nil {
+			replicas = max(*t.hpa.Spec.MinReplicas, replicas)
+		}
+		t.hpa.Spec.MaxReplicas = replicas
+		return replicas
+	}
+	replicas = min(t.hpa.Spec.MaxReplicas, replicas)
+	t.hpa.Spec.MinReplicas = &replicas
+	return replicas
+}
+
+// scaledObjectTarget drives one of the bounds of a KEDA ScaledObject
+type scaledObjectTarget struct {
+	scaledObject *unstructured.Unstructured
+	mode         string
+}
+
+func (t *scaledObjectTarget) Object() client.Object {
+	return t.scaledObject
+}
+
+func (t *scaledObjectTarget) count(field string, def int64) int64 {
+	value, found, err := unstructured.NestedInt64(t.scaledObject.Object, "spec", field)
+	if err != nil || !found {
+		return def
+	}
+	return value
+}
+
+func (t *scaledObjectTarget) Replicas() int32 {
+	if t.mode == schedulev1.MODE_MAX_REPLICAS {
+		return int32(t.count("maxReplicaCount", defaultScaledObjectMaxReplicaCount))
+	}
+	return int32(t.count("minReplicaCount", defaultScaledObjectMinReplicaCount))
+}
+
+func (t *scaledObjectTarget) SetReplicas(replicas int32) int32 {
+	// a ScaledObject requires 0 <= minReplicaCount <= maxReplicaCount
+	replicas = max(0, replicas)
+	field := "minReplicaCount"
+	if t.mode == schedulev1.MODE_MAX_REPLICAS {
+		replicas = max(int32(t.count("minReplicaCount", defaultScaledObjectMinReplicaCount)), replicas)
+		field = "maxReplicaCount"
+	} else {
+		replicas = min(int32(t.count("maxReplicaCount", defaultScaledObjectMaxReplicaCount)), replicas)
+	}
+	// SetNestedField only fails when an intermediate field is not a map, which the api server rejects anyway
+	_ = unstructured.SetNestedField(t.scaledObject.Object, int64(replicas), "spec", field)
+	return replicas
+}
+
+// getScaleTarget fetches the object referenced by .Spec.ScaleTargetRef
+func (r *EifaReplicaReconciler) getScaleTarget(ctx context.Context, eifaReplica *schedulev1.EifaReplica) (scaleTarget, error) {
+	key := client.ObjectKey{Namespace: eifaReplica.Namespace, Name: eifaReplica.Spec.ScaleTargetRef.Name}
+
+	switch normalizeKind(eifaReplica.Spec.ScaleTargetRef.Kind) {
+	case kindDeployment:
+		deployment := &appsv1.Deployment{}
+		if err := r.Get(ctx, key, deployment); err != nil {
+			return nil, err
+		}
+		return &deploymentTarget{deployment: deployment}, nil
+	case kindHorizontalPodAutoscaler:
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		if err := r.Get(ctx, key, hpa); err != nil {
+			return nil, err
+		}
+		return &hpaTarget{hpa: hpa, mode: targetMode(eifaReplica)}, nil
+	case kindScaledObject:
+		scaledObject := &unstructured.Unstructured{}
+		scaledObject.SetGroupVersionKind(scaledObjectGVK)
+		if err := r.Get(ctx, key, scaledObject); err != nil {
+			return nil, err
+		}
+		return &scaledObjectTarget{scaledObject: scaledObject, mode: targetMode(eifaReplica)}, nil
+	}
+	return nil, fmt.Errorf("unsupported .Spec.ScaleTargetRef.Kind %s", eifaReplica.Spec.ScaleTargetRef.Kind)
+}
+
+// targetMode returns the autoscaler bound driven by eifaReplica
+func targetMode(eifaReplica *schedulev1.EifaReplica) string {
+	if eifaReplica.Spec.ScaleTargetRef.Mode == "" {
+		return schedulev1.MODE_MIN_REPLICAS
+	}
+	return eifaReplica.Spec.ScaleTargetRef.Mode
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("ScaledObject target", func() {
+	const resourceName = "test-scaledobject"
+	key := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+	BeforeEach(func() {
+		By("creating a ScaledObject from the stub CRD")
+		scaledObject := &unstructured.Unstructured{}
+		scaledObject.SetGroupVersionKind(scaledObjectGVK)
+		scaledObject.SetName(key.Name)
+		scaledObject.SetNamespace(key.Namespace)
+		Expect(unstructured.SetNestedField(scaledObject.Object, "nginx", "spec", "scaleTargetRef", "name")).To(Succeed())
+		Expect(unstructured.SetNestedField(scaledObject.Object, int64(10), "spec", "maxReplicaCount")).To(Succeed())
+		Expect(k8sClient.Create(ctx, scaledObject)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		scaledObject := &unstructured.Unstructured{}
+		scaledObject.SetGroupVersionKind(scaledObjectGVK)
+		Expect(k8sClient.Get(ctx, key, scaledObject)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, scaledObject)).To(Succeed())
+	})
+
+	getTarget := func(mode string) scaleTarget {
+		reconciler := &EifaReplicaReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		target, err := reconciler.getScaleTarget(ctx, &schedulev1.EifaReplica{
+			Spec: schedulev1.EifaReplicaSpec{
+				ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "ScaledObject", Name: key.Name, Mode: mode},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return target
+	}
+
+	It("should patch minReplicaCount", func() {
+		target := getTarget("")
+		Expect(target.Replicas()).To(Equal(int32(0)))
+		Expect(target.SetReplicas(4)).To(Equal(int32(4)))
+		Expect(k8sClient.Update(ctx, target.Object())).To(Succeed())
+
+		Expect(getTarget("").Replicas()).To(Equal(int32(4)))
+	})
+
+	It("should keep minReplicaCount below maxReplicaCount", func() {
+		target := getTarget(schedulev1.MODE_MIN_REPLICAS)
+		Expect(target.SetReplicas(25)).To(Equal(int32(10)))
+	})
+
+	It("should patch maxReplicaCount", func() {
+		target := getTarget(schedulev1.MODE_MAX_REPLICAS)
+		Expect(target.Replicas()).To(Equal(int32(10)))
+		Expect(target.SetReplicas(20)).To(Equal(int32(20)))
+		Expect(k8sClient.Update(ctx, target.Object())).To(Succeed())
+
+		Expect(getTarget(schedulev1.MODE_MAX_REPLICAS).Replicas()).To(Equal(int32(20)))
+	})
+})
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// timelineStatusLimit is the most points of a timeline kept in the status, larger ones are stored in a ConfigMap
+const timelineStatusLimit = 100
+
+// timelineMaxPoints is the most points of a timeline, so it fits in a ConfigMap
+const timelineMaxPoints = 10000
+
+// timelineKey is the key of the points in the timeline ConfigMap
+const timelineKey = "timeline"
+
+// parseTimeline parses a timeline printed as a JSON array of points in increasing time order,
+// such as [{"at": "2025-03-10T09:00:00Z", "replicas": 8}, ...]
+func parseTimeline(line string) ([]schedulev1.TimelinePoint, error) {
+	points := []schedulev1.TimelinePoint{}
+	if err := json.Unmarshal([]byte(line), &points); err != nil {
+		return nil, fmt.Errorf("can not parse log to timeline, %s", err)
+	}
+	if len(points) == 0 || len(points) > timelineMaxPoints {
+		return nil, fmt.Errorf("timeline must have between 1 and %d points, got %d", timelineMaxPoints, len(points))
+	}
+	for i := range points {
+		if points[i].At.IsZero() {
+			return nil, fmt.Errorf("timeline point %d has no time", i)
+		}
+		if i > 0 && !points[i-1].At.Before(&points[i].At) {
+			return nil, fmt.Errorf("timeline points must be in increasing time order, point %d is at %s after %s",
+				i, points[i].At.Format(time.RFC3339), points[i-1].At.Format(time.RFC3339))
+		}
+	}
+	return points, nil
+}
+
+// timelineConfigMapName returns the name of the ConfigMap holding the large timelines of eifaReplica
+func timelineConfigMapName(eifaReplica *schedulev1.EifaReplica) string {
+	return eifaReplica.Name + "-timeline"
+}
+
+// storeTimeline replaces the timeline of eifaReplica with points, which are kept in the status or in
+// a ConfigMap owned by eifaReplica when they are too many for it
+func (r *EifaReplicaReconciler) storeTimeline(ctx context.Context, eifaReplica *schedulev1.EifaReplica, points []schedulev1.TimelinePoint) error {
+	timeline := &schedulev1.TimelineStatus{
+		Length:   int32(len(points)),
+		NextTime: points[0].At.DeepCopy(),
+		EndTime:  points[len(points)-1].At,
+	}
+	if len(points) <= timelineStatusLimit {
+		if err := r.dropTimeline(ctx, eifaReplica); err != nil {
+			return err
+		}
+		timeline.Points = points
+		eifaReplica.Status.Timeline = timeline
+		return nil
+	}
+
+	data, err := json.Marshal(points)
+	if err != nil {
+		return fmt.Errorf("can not marshal the timeline, %s", err)
+	}
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: eifaReplica.Namespace, Name: timelineConfigMapName(eifaReplica)}
+	if err := r.uncachedReader().Get(ctx, key, configMap); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("can not get the timeline configmap %s, %s", key.Name, err)
+		}
+		configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+		if err := ctrl.SetControllerReference(eifaReplica, configMap, r.Scheme); err != nil {
+			return fmt.Errorf("can not set owner ref, %s", err)
+		}
+		configMap.Data = map[string]string{timelineKey: string(data)}
+		if err := r.Create(ctx, configMap); err != nil {
+			return fmt.Errorf("can not create the timeline configmap %s, %s", key.Name, err)
+		}
+	} else {
+		configMap.Data = map[string]string{timelineKey: string(data)}
+		if err := r.Update(ctx, configMap); err != nil {
+			return fmt.Errorf("can not update the timeline configmap %s, %s", key.Name, err)
+		}
+	}
+	timeline.ConfigMapName = key.Name
+	eifaReplica.Status.Timeline = timeline
+	return nil
+}
+
+// dropTimeline drops the timeline of eifaReplica, replaced by the output of a job
+func (r *EifaReplicaReconciler) dropTimeline(ctx context.Context, eifaReplica *schedulev1.EifaReplica) error {
+	timeline := eifaReplica.Status.Timeline
+	if timeline == nil {
+		return nil
+	}
+	if timeline.ConfigMapName != "" {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: eifaReplica.Namespace, Name: timeline.ConfigMapName}}
+		if err := r.Delete(ctx, configMap); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("can not delete the timeline configmap %s, %s", configMap.Name, err)
+		}
+	}
+	eifaReplica.Status.Timeline = nil
+	return nil
+}
+
+// timelinePoints returns the points of the timeline of eifaReplica, the timeline is dropped when its
+// ConfigMap was deleted or altered
+func (r *EifaReplicaReconciler) timelinePoints(ctx context.Context, eifaReplica *schedulev1.EifaReplica) ([]schedulev1.TimelinePoint, error) {
+	timeline := eifaReplica.Status.Timeline
+	if timeline.ConfigMapName == "" {
+		return timeline.Points, nil
+	}
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: eifaReplica.Namespace, Name: timeline.ConfigMapName}
+	if err := r.uncachedReader().Get(ctx, key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			eifaReplica.Status.Timeline = nil
+			return nil, fmt.Errorf("timeline configmap %s not found, dropping the timeline", key.Name)
+		}
+		return nil, fmt.Errorf("can not get the timeline configmap %s, %s", key.Name, err)
+	}
+	points := []schedulev1.TimelinePoint{}
+	if err := json.Unmarshal([]byte(configMap.Data[timelineKey]), &points); err != nil || len(points) != int(timeline.Length) {
+		eifaReplica.Status.Timeline = nil
+		return nil, fmt.Errorf("timeline configmap %s does not hold the %d points of the timeline, dropping the timeline",
+			key.Name, timeline.Length)
+	}
+	return points, nil
+}
+
+// nextPoint advances timeline to now and returns the replicas of the last point reached, the points
+// reached meanwhile are skipped, false when no point is due
+func nextPoint(timeline *schedulev1.TimelineStatus, points []schedulev1.TimelinePoint, now time.Time) (int32, bool) {
+	due := -1
+	for i := int(timeline.Applied); i < len(points) && !now.Before(points[i].At.Time); i++ {
+		due = i
+	}
+	if due < 0 {
+		return 0, false
+	}
+	timeline.Applied = int32(due + 1)
+	timeline.NextTime = nil
+	if due+1 < len(points) {
+		timeline.NextTime = points[due+1].At.DeepCopy()
+	}
+	return points[due].Replicas, true
+}
+
+// timelineDue returns the replicas of the point of the timeline of eifaReplica reached at now,
+// false when there is none
+func (r *EifaReplicaReconciler) timelineDue(ctx context.Context, eifaReplica *schedulev1.EifaReplica, now time.Time) (int32, bool, error) {
+	timeline := eifaReplica.Status.Timeline
+	if timeline == nil || timeline.NextTime == nil || now.Before(timeline.NextTime.Time) {
+		return 0, false, nil
+	}
+	points, err := r.timelinePoints(ctx, eifaReplica)
+	if err != nil {
+		return 0, false, err
+	}
+	replicas, ok := nextPoint(timeline, points, now)
+	return replicas, ok, nil
+}
+
+// describeTimeline describes timeline, where it is stored and its next point
+func describeTimeline(timeline *schedulev1.TimelineStatus) string {
+	msg := fmt.Sprintf("job printed a timeline of %d points until %s", timeline.Length, timeline.EndTime.Format(time.RFC3339))
+	if timeline.ConfigMapName != "" {
+		msg += fmt.Sprintf(", stored in configmap %s", timeline.ConfigMapName)
+	}
+	if timeline.NextTime != nil {
+		msg += fmt.Sprintf(", next point at %s", timeline.NextTime.Format(time.RFC3339))
+	}
+	return msg
+}
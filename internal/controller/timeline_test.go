@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Timeline", func() {
+	ctx := context.Background()
+	nine := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	var er *schedulev1.EifaReplica
+	var reconciler *EifaReplicaReconciler
+
+	// hourly points from nine, the replicas of point i are i+1
+	timeline := func(n int) []schedulev1.TimelinePoint {
+		points := []schedulev1.TimelinePoint{}
+		for i := 0; i < n; i++ {
+			points = append(points, schedulev1.TimelinePoint{At: metav1.NewTime(nine.Add(time.Duration(i) * time.Hour)), Replicas: int32(i + 1)})
+		}
+		return points
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(schedulev1.AddToScheme(scheme)).To(Succeed())
+
+		er = &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "web-uid"},
+			Spec:       schedulev1.EifaReplicaSpec{MinReplicas: 2, MaxReplicas: 200},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(er).Build()
+		reconciler = &EifaReplicaReconciler{Client: c, Scheme: scheme}
+	})
+
+	It("should parse a timeline output", func() {
+		result, err := parseJobOutput(`[{"at": "2025-03-10T09:00:00Z", "replicas": 4}, {"at": "2025-03-10T10:00:00Z", "replicas": 9}]`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Timeline).To(HaveLen(2))
+		Expect(result.Timeline[1].At.Time).To(BeTemporally("==", nine.Add(time.Hour)))
+		Expect(result.Timeline[1].Replicas).To(Equal(int32(9)))
+
+		_, err = parseJobOutput(`[]`)
+		Expect(err).To(HaveOccurred())
+		_, err = parseJobOutput(`[{"at": "2025-03-10T10:00:00Z", "replicas": 4}, {"at": "2025-03-10T09:00:00Z", "replicas": 9}]`)
+		Expect(err).To(MatchError(ContainSubstring("increasing time order")))
+		_, err = parseJobOutput(`[{"replicas": 4}]`)
+		Expect(err).To(MatchError(ContainSubstring("no time")))
+	})
+
+	It("should apply the last point reached", func() {
+		points := timeline(4)
+		Expect(reconciler.storeTimeline(ctx, er, points)).To(Succeed())
+		Expect(er.Status.Timeline.Points).To(HaveLen(4))
+
+		_, ok := nextPoint(er.Status.Timeline, points, nine.Add(-time.Minute))
+		Expect(ok).To(BeFalse())
+
+		replicas, ok := nextPoint(er.Status.Timeline, points, nine)
+		Expect(ok).To(BeTrue())
+		Expect(replicas).To(Equal(int32(1)))
+		Expect(er.Status.Timeline.NextTime.Time).To(BeTemporally("==", nine.Add(time.Hour)))
+
+		// the points missed meanwhile are skipped
+		replicas, ok = nextPoint(er.Status.Timeline, points, nine.Add(150*time.Minute))
+		Expect(ok).To(BeTrue())
+		Expect(replicas).To(Equal(int32(3)))
+		Expect(er.Status.Timeline.Applied).To(Equal(int32(3)))
+
+		replicas, ok, err := reconciler.timelineDue(ctx, er, nine.Add(3*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(replicas).To(Equal(int32(4)))
+		Expect(er.Status.Timeline.NextTime).To(BeNil())
+
+		_, ok, err = reconciler.timelineDue(ctx, er, nine.Add(4*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should store a large timeline in a configmap", func() {
+		points := timeline(timelineStatusLimit + 1)
+		Expect(reconciler.storeTimeline(ctx, er, points)).To(Succeed())
+		Expect(er.Status.Timeline.Points).To(BeEmpty())
+		Expect(er.Status.Timeline.ConfigMapName).To(Equal("web-timeline"))
+		Expect(er.Status.Timeline.Length).To(Equal(int32(timelineStatusLimit + 1)))
+
+		configMap := &corev1.ConfigMap{}
+		Expect(reconciler.Get(ctx, client.ObjectKey{Namespace: "default", Name: "web-timeline"}, configMap)).To(Succeed())
+		Expect(configMap.OwnerReferences).To(HaveLen(1))
+
+		replicas, ok, err := reconciler.timelineDue(ctx, er, nine.Add(time.Duration(timelineStatusLimit)*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(replicas).To(Equal(int32(timelineStatusLimit + 1)))
+
+		// a small timeline replaces it in the status
+		Expect(reconciler.storeTimeline(ctx, er, timeline(2))).To(Succeed())
+		Expect(er.Status.Timeline.ConfigMapName).To(BeEmpty())
+		err = reconciler.Get(ctx, client.ObjectKey{Namespace: "default", Name: "web-timeline"}, &corev1.ConfigMap{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should drop a timeline whose configmap was deleted", func() {
+		Expect(reconciler.storeTimeline(ctx, er, timeline(timelineStatusLimit+1))).To(Succeed())
+		Expect(reconciler.Delete(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web-timeline"}})).To(Succeed())
+
+		_, _, err := reconciler.timelineDue(ctx, er, nine)
+		Expect(err).To(MatchError(ContainSubstring("dropping the timeline")))
+		Expect(er.Status.Timeline).To(BeNil())
+	})
+
+	It("should keep a timeline fresh until its last point", func() {
+		er.Spec.MaxResultAge = &metav1.Duration{Duration: 2 * time.Hour}
+		er.Status.LastSuccessfulRunTime = &metav1.Time{Time: nine.Add(-time.Hour)}
+		points := timeline(6)
+		Expect(reconciler.storeTimeline(ctx, er, points)).To(Succeed())
+		_, ok := resultExpiry(er)
+		Expect(ok).To(BeFalse())
+
+		nextPoint(er.Status.Timeline, points, nine.Add(5*time.Hour))
+		expiry, ok := resultExpiry(er)
+		Expect(ok).To(BeTrue())
+		Expect(expiry).To(BeTemporally("==", nine.Add(7*time.Hour)))
+	})
+
+	It("should describe the timeline", func() {
+		Expect(reconciler.storeTimeline(ctx, er, timeline(3))).To(Succeed())
+		msg := describeTimeline(er.Status.Timeline)
+		Expect(msg).To(ContainSubstring(fmt.Sprintf("3 points until %s", nine.Add(2*time.Hour).Format(time.RFC3339))))
+		Expect(strings.Contains(msg, "configmap")).To(BeFalse())
+	})
+})
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// verifyPollInterval is how often the readiness of a target is checked when its deployment is not watched
+// through the target index, such as behind a ScaledObject
+const verifyPollInterval = 10 * time.Second
+
+// defaultVerifyTimeout is the readiness timeout when .Spec.Verify.TimeoutSeconds is unset
+const defaultVerifyTimeout = 300 * time.Second
+
+// startVerification records the scale up of the target from from to to as waiting for its pods to become
+// ready, it returns false when the scale up is not verified. A rollback restores the replicas written before
+// the ramp the scale up is a step of, or before the earlier scale up still waiting for its pods.
+func startVerification(eifaReplica *schedulev1.EifaReplica, from, to int32, now time.Time) bool {
+	rollbackTo := from
+	if ramp := eifaReplica.Status.Ramp; ramp != nil && ramp.From < rollbackTo {
+		rollbackTo = ramp.From
+	}
+	if previous := eifaReplica.Status.Verification; previous != nil && rollbackReplicas(previous) < rollbackTo {
+		rollbackTo = rollbackReplicas(previous)
+	}
+	eifaReplica.Status.Verification = nil
+	autoscalerMax := normalizeKind(eifaReplica.Spec.ScaleTargetRef.Kind) != kindDeployment &&
+		targetMode(eifaReplica) == schedulev1.MODE_MAX_REPLICAS
+	if eifaReplica.Spec.Verify == nil || to <= from || autoscalerMax {
+		return false
+	}
+	timeout := defaultVerifyTimeout
+	if eifaReplica.Spec.Verify.TimeoutSeconds > 0 {
+		timeout = time.Duration(eifaReplica.Spec.Verify.TimeoutSeconds) * time.Second
+	}
+	eifaReplica.Status.Verification = &schedulev1.Verification{
+		From:       from,
+		To:         to,
+		RollbackTo: &rollbackTo,
+		StartTime:  metav1.NewTime(now),
+		Deadline:   metav1.NewTime(now.Add(timeout)),
+	}
+	return true
+}
+
+// rollbackReplicas returns the replicas restored by a rollback of verification
+func rollbackReplicas(verification *schedulev1.Verification) int32 {
+	if verification.RollbackTo != nil {
+		return *verification.RollbackTo
+	}
+	return verification.From
+}
+
+// deploymentReady reports whether the deployment rolled out at least replicas ready pods
+func deploymentReady(deployment *appsv1.Deployment, replicas int32) bool {
+	return deployment.Status.ObservedGeneration >= deployment.Generation && deployment.Status.ReadyReplicas >= replicas
+}
+
+// verifyTarget checks the readiness of the scale up being verified, the outcome is recorded as a condition
+// and the target is rolled back when the timeout is reached with the Rollback action. It returns true when
+// the target was rolled back, so nothing else is written within the reconciliation.
+func (r *EifaReplicaReconciler) verifyTarget(ctx context.Context, eifaReplica *schedulev1.EifaReplica, now time.Time, next *time.Time) (bool, error) {
+	verification := eifaReplica.Status.Verification
+	target, err := r.getScaleTarget(ctx, eifaReplica)
+	if err != nil {
+		return false, fmt.Errorf("can not get the target, %s", err)
+	}
+	deployment, err := r.workload(ctx, eifaReplica.Namespace, target)
+	if err != nil {
+		return false, fmt.Errorf("can not get the deployment of the target, %s", err)
+	}
+	if deployment == nil || deploymentReady(deployment, verification.To) {
+		eifaReplica.Status.Verification = nil
+		return false, r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+			Type:               schedulev1.PROGRESSING,
+			Status:             metav1.ConditionFalse,
+			LastTransitionTime: metav1.Now(),
+			Reason:             "ReplicasReady",
+			Message:            fmt.Sprintf("target reached %d ready replicas", verification.To),
+		}, next)
+	}
+	if now.Before(verification.Deadline.Time) {
+		return false, nil
+	}
+
+	eifaReplica.Status.Verification = nil
+	msg := fmt.Sprintf("target has %d ready replicas out of %d after %s",
+		deployment.Status.ReadyReplicas, verification.To, verification.Deadline.Sub(verification.StartTime.Time))
+	rollback := eifaReplica.Spec.Verify != nil && eifaReplica.Spec.Verify.OnFailure == schedulev1.VERIFY_ROLLBACK
+	if rollback {
+		current := target.Replicas()
+		rollbackTo := rollbackReplicas(verification)
+		applied := target.SetReplicas(rollbackTo)
+		if err := r.Update(ctx, target.Object()); err != nil {
+			return false, fmt.Errorf("can not roll back the target replicas to %d, %s", rollbackTo, err)
+		}
+		recordScaleEvent(eifaReplica, applied-current, now)
+		observeReplicas(eifaReplica, applied, &applied)
+		// forget the failed scale up so neither a deferral nor a reallocation writes it again,
+		// the desired replicas are retried with the next job result
+		eifaReplica.Status.DesiredReplicas = nil
+		eifaReplica.Status.Ramp = nil
+		eifaReplica.Status.DeferredUntil = nil
+		msg += fmt.Sprintf(", rolled back target replica from %d to %d", current, applied)
+	}
+	r.Recorder.Event(eifaReplica, corev1.EventTypeWarning, "ReadinessTimeout", msg)
+	return rollback, r.UpdateStatus(ctx, eifaReplica, &metav1.Condition{
+		Type:               schedulev1.DEGRADED,
+		Status:             metav1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "ReadinessTimeout",
+		Message:            msg,
+	}, next)
+}
+
+// eifaReplicasOfDeployment maps a deployment to the EifaReplicas verifying or pre-warming a scale up of it,
+// directly or through a HorizontalPodAutoscaler
+func (r *EifaReplicaReconciler) eifaReplicasOfDeployment(ctx context.Context, deployment client.Object) []reconcile.Request {
+	keys := []string{targetKey(kindDeployment, deployment.GetName())}
+	hpas := &autoscalingv2.HorizontalPodAutoscalerList{}
+	if err := r.List(ctx, hpas, client.InNamespace(deployment.GetNamespace()),
+		client.MatchingFields{scaleTargetIndex: targetKey(kindDeployment, deployment.GetName())}); err == nil {
+		for _, hpa := range hpas.Items {
+			keys = append(keys, targetKey(kindHorizontalPodAutoscaler, hpa.Name))
+		}
+	}
+
+	requests := []reconcile.Request{}
+	for _, key := range keys {
+		eifaReplicas := &schedulev1.EifaReplicaList{}
+		if err := r.List(ctx, eifaReplicas, client.InNamespace(deployment.GetNamespace()),
+			client.MatchingFields{scaleTargetIndex: key}); err != nil {
+			continue
+		}
+		for _, eifaReplica := range eifaReplicas.Items {
+			if eifaReplica.Status.Verification != nil || eifaReplica.Status.Prewarm != nil {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&eifaReplica)})
+			}
+		}
+	}
+	return requests
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Verify", func() {
+	ctx := context.Background()
+	now := time.Now()
+	var er *schedulev1.EifaReplica
+	var deployment *appsv1.Deployment
+	var reconciler *EifaReplicaReconciler
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(schedulev1.AddToScheme(scheme)).To(Succeed())
+
+		er = &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: schedulev1.EifaReplicaSpec{
+				ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "Deployment", Name: "web"},
+				Verify:         &schedulev1.Verify{TimeoutSeconds: 120, OnFailure: schedulev1.VERIFY_ROLLBACK},
+			},
+		}
+		replicas := int32(10)
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 6},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(er, deployment).
+			WithStatusSubresource(er).Build()
+		reconciler = &EifaReplicaReconciler{Client: c, Scheme: scheme, Recorder: record.NewFakeRecorder(10)}
+	})
+
+	It("should only verify scale ups", func() {
+		Expect(startVerification(er, 10, 4, now)).To(BeFalse())
+		Expect(startVerification(er, 4, 10, now)).To(BeTrue())
+		Expect(er.Status.Verification.Deadline.Time).To(BeTemporally("==", now.Add(2*time.Minute)))
+		Expect(pendingRequeue(er, time.Hour)).To(BeNumerically("<=", verifyPollInterval))
+
+		er.Spec.ScaleTargetRef = schedulev1.ScaleTargetRef{Kind: "HorizontalPodAutoscaler", Name: "web", Mode: schedulev1.MODE_MAX_REPLICAS}
+		Expect(startVerification(er, 4, 10, now)).To(BeFalse())
+		Expect(er.Status.Verification).To(BeNil())
+	})
+
+	It("should wait for the ready replicas until the deadline", func() {
+		startVerification(er, 4, 10, now)
+		rolledBack, err := reconciler.verifyTarget(ctx, er, now.Add(time.Minute), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rolledBack).To(BeFalse())
+		Expect(er.Status.Verification).NotTo(BeNil())
+
+		deployment.Status.ReadyReplicas = 10
+		Expect(reconciler.Status().Update(ctx, deployment)).To(Succeed())
+		rolledBack, err = reconciler.verifyTarget(ctx, er, now.Add(time.Minute), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rolledBack).To(BeFalse())
+		Expect(er.Status.Verification).To(BeNil())
+		Expect(er.Status.Conditions[len(er.Status.Conditions)-1].Reason).To(Equal("ReplicasReady"))
+	})
+
+	It("should roll back after the deadline", func() {
+		startVerification(er, 4, 10, now)
+		rolledBack, err := reconciler.verifyTarget(ctx, er, now.Add(2*time.Minute), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rolledBack).To(BeTrue())
+		Expect(er.Status.Verification).To(BeNil())
+		Expect(er.Status.Conditions[len(er.Status.Conditions)-1].Type).To(Equal(schedulev1.DEGRADED))
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(4)))
+	})
+
+	It("should roll back a ramp to the replicas before it", func() {
+		desired := int32(16)
+		er.Status.DesiredReplicas = &desired
+		er.Status.Ramp = &schedulev1.RampStatus{From: 4, To: 16, Steps: 3, Step: 2, StartTime: metav1.NewTime(now)}
+		startVerification(er, 8, 10, now)
+		Expect(*er.Status.Verification.RollbackTo).To(Equal(int32(4)))
+
+		deferredUntil := metav1.NewTime(now.Add(time.Minute))
+		er.Status.DeferredUntil = &deferredUntil
+		rolledBack, err := reconciler.verifyTarget(ctx, er, now.Add(2*time.Minute), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rolledBack).To(BeTrue())
+		Expect(er.Status.DesiredReplicas).To(BeNil())
+		Expect(er.Status.DeferredUntil).To(BeNil())
+		Expect(er.Status.Ramp).To(BeNil())
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(4)))
+	})
+
+	It("should only report with the Report action", func() {
+		er.Spec.Verify.OnFailure = schedulev1.VERIFY_REPORT
+		startVerification(er, 4, 10, now)
+		rolledBack, err := reconciler.verifyTarget(ctx, er, now.Add(2*time.Minute), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rolledBack).To(BeFalse())
+		Expect(er.Status.Conditions[len(er.Status.Conditions)-1].Type).To(Equal(schedulev1.DEGRADED))
+
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(10)))
+	})
+})
@@ -0,0 +1,117 @@
+package externalmetrics
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the kube-aggregator publishes the configuration of its proxy client certificate in this ConfigMap
+var authenticationConfigMap = client.ObjectKey{Namespace: "kube-system", Name: "extension-apiserver-authentication"}
+
+// reload the ConfigMap periodically, so a rotated CA is picked up without a restart
+const authenticationConfigTTL = 10 * time.Minute
+
+type userInfo struct {
+	name   string
+	groups []string
+}
+
+type requestHeaderConfig struct {
+	caPool          *x509.CertPool
+	allowedNames    []string
+	usernameHeaders []string
+	groupHeaders    []string
+	loaded          time.Time
+}
+
+// requestHeaderAuthenticator authenticates requests proxied by the kube-aggregator: the client
+// certificate must be signed by the request header CA, the user is then read from the headers.
+type requestHeaderAuthenticator struct {
+	reader client.Reader
+
+	mu     sync.Mutex
+	config *requestHeaderConfig
+}
+
+func (a *requestHeaderAuthenticator) authenticate(ctx context.Context, req *http.Request) (*userInfo, error) {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("no client certificate provided")
+	}
+	config, err := a.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range req.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	cert := req.TLS.PeerCertificates[0]
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         config.caPool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return nil, fmt.Errorf("invalid client certificate, %s", err)
+	}
+	if len(config.allowedNames) > 0 && !slices.Contains(config.allowedNames, cert.Subject.CommonName) {
+		return nil, fmt.Errorf("client certificate %s is not allowed", cert.Subject.CommonName)
+	}
+
+	user := &userInfo{}
+	for _, header := range config.usernameHeaders {
+		if user.name = req.Header.Get(header); user.name != "" {
+			break
+		}
+	}
+	if user.name == "" {
+		return nil, fmt.Errorf("no user provided in the request headers")
+	}
+	for _, header := range config.groupHeaders {
+		user.groups = append(user.groups, req.Header.Values(header)...)
+	}
+	return user, nil
+}
+
+// load returns the request header configuration, reading it from the api server when it is missing or expired
+func (a *requestHeaderAuthenticator) load(ctx context.Context) (*requestHeaderConfig, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.config != nil && time.Since(a.config.loaded) < authenticationConfigTTL {
+		return a.config, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := a.reader.Get(ctx, authenticationConfigMap, configMap); err != nil {
+		return nil, fmt.Errorf("can not get %s, %s", authenticationConfigMap, err)
+	}
+
+	config := &requestHeaderConfig{caPool: x509.NewCertPool(), loaded: time.Now()}
+	if !config.caPool.AppendCertsFromPEM([]byte(configMap.Data["requestheader-client-ca-file"])) {
+		return nil, fmt.Errorf("%s has no request header client CA", authenticationConfigMap)
+	}
+	for key, value := range map[string]*[]string{
+		"requestheader-allowed-names":    &config.allowedNames,
+		"requestheader-username-headers": &config.usernameHeaders,
+		"requestheader-group-headers":    &config.groupHeaders,
+	} {
+		if raw, ok := configMap.Data[key]; ok && raw != "" {
+			if err := json.Unmarshal([]byte(raw), value); err != nil {
+				return nil, fmt.Errorf("can not parse %s of %s, %s", key, authenticationConfigMap, err)
+			}
+		}
+	}
+
+	a.config = config
+	return config, nil
+}
@@ -0,0 +1,173 @@
+package externalmetrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var log = logf.Log.WithName("external-metrics")
+
+// MetricLabel is the label which identifies the EifaReplica of a metric value
+const MetricLabel = "eifareplica"
+
+// Provider serves the desired replicas of every EifaReplica as an external metric named after it,
+// so an HPA in the same namespace can consume it next to its resource metrics.
+// It is meant to be registered on the webhook server behind an APIService.
+type Provider struct {
+	client        client.Client
+	authenticator *requestHeaderAuthenticator
+}
+
+// NewProvider returns a provider reading EifaReplicas through c, apiReader is used
+// to load the request header configuration of the kube-aggregator.
+func NewProvider(c client.Client, apiReader client.Reader) *Provider {
+	return &Provider{
+		client:        c,
+		authenticator: &requestHeaderAuthenticator{reader: apiReader},
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (p *Provider) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeStatus(w, apierrors.NewMethodNotSupported(schema.GroupResource{Group: Group}, req.Method))
+		return
+	}
+
+	user, err := p.authenticator.authenticate(req.Context(), req)
+	if err != nil {
+		log.V(1).Info("unauthenticated request", "path", req.URL.Path, "reason", err.Error())
+		writeStatus(w, apierrors.NewUnauthorized(err.Error()))
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, APIPath), "/")
+	if path == "" {
+		writeJSON(w, http.StatusOK, &metav1.APIResourceList{
+			TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+			GroupVersion: Group + "/" + Version,
+			APIResources: []metav1.APIResource{},
+		})
+		return
+	}
+
+	// namespaces/{namespace}/{metric}
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 || parts[0] != "namespaces" {
+		writeStatus(w, apierrors.NewNotFound(schema.GroupResource{Group: Group}, path))
+		return
+	}
+	namespace, metricName := parts[1], parts[2]
+
+	if err := p.authorize(req.Context(), user, namespace, metricName); err != nil {
+		writeStatus(w, err)
+		return
+	}
+
+	selector := labels.Everything()
+	if raw := req.URL.Query().Get("labelSelector"); raw != "" {
+		if selector, err = labels.Parse(raw); err != nil {
+			writeStatus(w, apierrors.NewBadRequest(fmt.Sprintf("invalid labelSelector, %s", err)))
+			return
+		}
+	}
+
+	list, err := p.getMetric(req.Context(), namespace, metricName, selector)
+	if err != nil {
+		writeStatus(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// getMetric returns the desired replicas of the EifaReplica named metricName
+func (p *Provider) getMetric(ctx context.Context, namespace, metricName string, selector labels.Selector) (*ExternalMetricValueList, error) {
+	eifaReplica := &schedulev1.EifaReplica{}
+	if err := p.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: metricName}, eifaReplica); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, apierrors.NewNotFound(schedulev1.GroupVersion.WithResource("eifareplicas").GroupResource(), metricName)
+		}
+		return nil, apierrors.NewInternalError(err)
+	}
+	if eifaReplica.Status.DesiredReplicas == nil {
+		return nil, apierrors.NewServiceUnavailable(fmt.Sprintf("EifaReplica %s has no successful job yet", metricName))
+	}
+
+	list := &ExternalMetricValueList{
+		TypeMeta: metav1.TypeMeta{Kind: "ExternalMetricValueList", APIVersion: Group + "/" + Version},
+		Items:    []ExternalMetricValue{},
+	}
+	metricLabels := map[string]string{MetricLabel: eifaReplica.Name}
+	if !selector.Matches(labels.Set(metricLabels)) {
+		return list, nil
+	}
+
+	timestamp := metav1.Now()
+	if eifaReplica.Status.LastSuccessfulRunTime != nil {
+		timestamp = *eifaReplica.Status.LastSuccessfulRunTime
+	}
+	list.Items = append(list.Items, ExternalMetricValue{
+		MetricName:   metricName,
+		MetricLabels: metricLabels,
+		Timestamp:    timestamp,
+		Value:        *resource.NewQuantity(int64(*eifaReplica.Status.DesiredReplicas), resource.DecimalSI),
+	})
+	return list, nil
+}
+
+// authorize checks with a SubjectAccessReview that user may get the metric
+func (p *Provider) authorize(ctx context.Context, user *userInfo, namespace, metricName string) error {
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.name,
+			Groups: user.groups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "get",
+				Group:     Group,
+				Version:   Version,
+				Resource:  metricName,
+			},
+		},
+	}
+	if err := p.client.Create(ctx, review); err != nil {
+		return apierrors.NewInternalError(fmt.Errorf("can not create SubjectAccessReview, %s", err))
+	}
+	if !review.Status.Allowed {
+		return apierrors.NewForbidden(schema.GroupResource{Group: Group, Resource: metricName}, metricName,
+			fmt.Errorf("user %s can not get external metric %s in namespace %s", user.name, metricName, namespace))
+	}
+	return nil
+}
+
+func writeStatus(w http.ResponseWriter, err error) {
+	status, ok := err.(apierrors.APIStatus)
+	if !ok {
+		status = apierrors.NewInternalError(err)
+	}
+	s := status.Status()
+	s.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	writeJSON(w, int(s.Code), &s)
+}
+
+func writeJSON(w http.ResponseWriter, code int, obj any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		log.Error(err, "can not write response")
+	}
+}
@@ -0,0 +1,59 @@
+package externalmetrics
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("Provider", func() {
+	ctx := context.Background()
+	var provider *Provider
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(schedulev1.AddToScheme(scheme)).To(Succeed())
+
+		desired := int32(7)
+		withResult := &schedulev1.EifaReplica{ObjectMeta: metav1.ObjectMeta{Name: "with-result", Namespace: "default"}}
+		withResult.Status.DesiredReplicas = &desired
+		withoutResult := &schedulev1.EifaReplica{ObjectMeta: metav1.ObjectMeta{Name: "without-result", Namespace: "default"}}
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(withResult, withoutResult).Build()
+		provider = NewProvider(c, c)
+	})
+
+	It("should serve the desired replicas", func() {
+		list, err := provider.getMetric(ctx, "default", "with-result", labels.Everything())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+		Expect(list.Items[0].Value.Value()).To(Equal(int64(7)))
+		Expect(list.Items[0].MetricLabels).To(HaveKeyWithValue(MetricLabel, "with-result"))
+	})
+
+	It("should filter by the label selector", func() {
+		selector, err := labels.Parse(MetricLabel + "=other")
+		Expect(err).NotTo(HaveOccurred())
+		list, err := provider.getMetric(ctx, "default", "with-result", selector)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(BeEmpty())
+	})
+
+	It("should fail for EifaReplicas without a result", func() {
+		_, err := provider.getMetric(ctx, "default", "without-result", labels.Everything())
+		Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+	})
+
+	It("should fail for unknown EifaReplicas", func() {
+		_, err := provider.getMetric(ctx, "default", "missing", labels.Everything())
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
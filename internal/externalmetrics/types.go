@@ -0,0 +1,36 @@
+package externalmetrics
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The types below mirror k8s.io/metrics/pkg/apis/external_metrics/v1beta1,
+// only the subset served by this provider is declared.
+
+const (
+	// Group is the API group served by the provider
+	Group = "external.metrics.k8s.io"
+	// Version is the API version served by the provider
+	Version = "v1beta1"
+	// APIPath is the root path of the served group version
+	APIPath = "/apis/" + Group + "/" + Version
+)
+
+// ExternalMetricValue is a metric value for an external metric
+type ExternalMetricValue struct {
+	metav1.TypeMeta `json:",inline"`
+
+	MetricName   string            `json:"metricName"`
+	MetricLabels map[string]string `json:"metricLabels"`
+	Timestamp    metav1.Time       `json:"timestamp"`
+	Value        resource.Quantity `json:"value"`
+}
+
+// ExternalMetricValueList is a list of values for a given metric for some set of labels
+type ExternalMetricValueList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []ExternalMetricValue `json:"items"`
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2025 Erfan Mahvash.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+// log is for logging in this package.
+var eifareplicalog = logf.Log.WithName("eifareplica-resource")
+
+// SetupEifaReplicaWebhookWithManager registers the webhook for EifaReplica in the manager.
+func SetupEifaReplicaWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&schedulev1.EifaReplica{}).
+		WithValidator(&EifaReplicaCustomValidator{}).
+		WithDefaulter(&EifaReplicaCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-schedule-eifa-org-v1-eifareplica,mutating=true,failurePolicy=fail,sideEffects=None,groups=schedule.eifa.org,resources=eifareplicas,verbs=create;update,versions=v1,name=meifareplica-v1.kb.io,admissionReviewVersions=v1
+
+// EifaReplicaCustomDefaulter struct is responsible for setting default values on the custom resource of the
+// Kind EifaReplica when those are created or updated.
+type EifaReplicaCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &EifaReplicaCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind EifaReplica.
+func (d *EifaReplicaCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	eifareplica, ok := obj.(*schedulev1.EifaReplica)
+	if !ok {
+		return fmt.Errorf("expected an EifaReplica object but got %T", obj)
+	}
+	eifareplicalog.Info("Defaulting for EifaReplica", "name", eifareplica.GetName())
+
+	if isAutoscalerKind(eifareplica.Spec.ScaleTargetRef.Kind) && eifareplica.Spec.ScaleTargetRef.Mode == "" {
+		eifareplica.Spec.ScaleTargetRef.Mode = schedulev1.MODE_MIN_REPLICAS
+	}
+
+	schedulev1.DefaultJobSpec(&eifareplica.Spec.JobTemplate.Spec)
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-schedule-eifa-org-v1-eifareplica,mutating=false,failurePolicy=fail,sideEffects=None,groups=schedule.eifa.org,resources=eifareplicas,verbs=create;update,versions=v1,name=veifareplica-v1.kb.io,admissionReviewVersions=v1
+
+// EifaReplicaCustomValidator struct is responsible for validating the EifaReplica resource
+// when it is created, updated, or deleted.
+type EifaReplicaCustomValidator struct{}
+
+var _ webhook.CustomValidator = &EifaReplicaCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type EifaReplica.
+func (v *EifaReplicaCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	eifareplica, ok := obj.(*schedulev1.EifaReplica)
+	if !ok {
+		return nil, fmt.Errorf("expected a EifaReplica object but got %T", obj)
+	}
+	eifareplicalog.Info("Validation for EifaReplica upon creation", "name", eifareplica.GetName())
+
+	return validateEifaReplica(eifareplica)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type EifaReplica.
+func (v *EifaReplicaCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	eifareplica, ok := newObj.(*schedulev1.EifaReplica)
+	if !ok {
+		return nil, fmt.Errorf("expected a EifaReplica object for the newObj but got %T", newObj)
+	}
+	eifareplicalog.Info("Validation for EifaReplica upon update", "name", eifareplica.GetName())
+
+	return validateEifaReplica(eifareplica)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type EifaReplica.
+func (v *EifaReplicaCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateEifaReplica validates the fields which can not be expressed with OpenAPI markers
+// and returns warnings for the job template fields that the controller overrides.
+func validateEifaReplica(eifareplica *schedulev1.EifaReplica) (admission.Warnings, error) {
+	var allErrs field.ErrorList
+	var warnings admission.Warnings
+	specPath := field.NewPath("spec")
+
+	if eifareplica.Spec.ScaleTargetRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("scaleTargetRef", "name"), "target name must be set"))
+	}
+
+	if isAutoscalerKind(eifareplica.Spec.ScaleTargetRef.Kind) {
+		if eifareplica.Spec.MinReplicas < 1 && strings.ToLower(eifareplica.Spec.ScaleTargetRef.Kind) != "scaledobject" {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("minReplicas"), eifareplica.Spec.MinReplicas,
+				"must be at least 1 when the target is a HorizontalPodAutoscaler"))
+		}
+	} else if eifareplica.Spec.ScaleTargetRef.Mode != "" {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored for %s targets",
+			specPath.Child("scaleTargetRef", "mode"), eifareplica.Spec.ScaleTargetRef.Kind))
+	}
+
+	if eifareplica.Spec.MaxReplicas < 1 {
+		allErrs = append(allErrs, field.Required(specPath.Child("maxReplicas"),
+			"must be at least 1, otherwise every job result is clamped to 0"))
+	} else if eifareplica.Spec.MinReplicas > eifareplica.Spec.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("minReplicas"), eifareplica.Spec.MinReplicas,
+			fmt.Sprintf("must be less than or equal to maxReplicas (%d)", eifareplica.Spec.MaxReplicas)))
+	}
+
+	if behavior := eifareplica.Spec.Behavior; behavior != nil && eifareplica.Spec.Mode == schedulev1.MODE_METRIC {
+		behaviorPath := specPath.Child("behavior")
+		if behavior.Ramp != nil {
+			warnings = append(warnings, fmt.Sprintf("%s is ignored in %s mode",
+				behaviorPath.Child("ramp"), schedulev1.MODE_METRIC))
+		}
+		if behavior.ScaleUp != nil && behavior.ScaleUp.CooldownSeconds != nil {
+			warnings = append(warnings, fmt.Sprintf("%s is ignored in %s mode",
+				behaviorPath.Child("scaleUp", "cooldownSeconds"), schedulev1.MODE_METRIC))
+		}
+		if behavior.ScaleDown != nil && behavior.ScaleDown.CooldownSeconds != nil {
+			warnings = append(warnings, fmt.Sprintf("%s is ignored in %s mode",
+				behaviorPath.Child("scaleDown", "cooldownSeconds"), schedulev1.MODE_METRIC))
+		}
+	}
+
+	if fallback := eifareplica.Spec.Fallback; fallback != nil {
+		set := 0
+		for _, ok := range []bool{fallback.Replicas != nil, fallback.KeepCurrent, fallback.ScaleToMax} {
+			if ok {
+				set++
+			}
+		}
+		if set != 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("fallback"), set,
+				"exactly one of replicas, keepCurrent and scaleToMax must be set"))
+		}
+	}
+
+	if eifareplica.Spec.MaxResultAge != nil && eifareplica.Spec.MaxResultAge.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxResultAge"), eifareplica.Spec.MaxResultAge.String(),
+			"must be greater than 0"))
+	}
+
+	if detection := eifareplica.Spec.AnomalyDetection; detection != nil {
+		factor, err := strconv.ParseFloat(detection.MaxDeviationFactor, 64)
+		if err != nil || factor <= 1 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("anomalyDetection", "maxDeviationFactor"),
+				detection.MaxDeviationFactor, "must be a number greater than 1"))
+		}
+	}
+
+	if approval := eifareplica.Spec.Approval; approval != nil {
+		approvalPath := specPath.Child("approval")
+		if approval.MinReplicaChange == nil && approval.MinPercentChange == nil {
+			allErrs = append(allErrs, field.Required(approvalPath,
+				"at least one of minReplicaChange and minPercentChange must be set"))
+		}
+		if mode := eifareplica.Spec.Mode; mode == schedulev1.MODE_METRIC || mode == schedulev1.MODE_SHADOW {
+			warnings = append(warnings, fmt.Sprintf("%s is ignored in %s mode", approvalPath, mode))
+		}
+	}
+
+	if maxCost := eifareplica.Spec.MaxHourlyCost; maxCost != nil {
+		costPath := specPath.Child("maxHourlyCost")
+		if cost, err := strconv.ParseFloat(*maxCost, 64); err != nil || cost <= 0 {
+			allErrs = append(allErrs, field.Invalid(costPath, *maxCost, "must be a number greater than 0"))
+		}
+		if eifareplica.Spec.Mode == schedulev1.MODE_METRIC {
+			warnings = append(warnings, fmt.Sprintf("%s is ignored in %s mode", costPath, schedulev1.MODE_METRIC))
+		}
+	}
+
+	if mode := eifareplica.Spec.Mode; eifareplica.Spec.Verify != nil && (mode == schedulev1.MODE_METRIC || mode == schedulev1.MODE_SHADOW) {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored in %s mode", specPath.Child("verify"), mode))
+	}
+	if mode := eifareplica.Spec.Mode; eifareplica.Spec.WaitForRollout && (mode == schedulev1.MODE_METRIC || mode == schedulev1.MODE_SHADOW) {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored in %s mode", specPath.Child("waitForRollout"), mode))
+	}
+	if mode := eifareplica.Spec.Mode; eifareplica.Spec.Prewarm != nil && (mode == schedulev1.MODE_METRIC || mode == schedulev1.MODE_SHADOW) {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored in %s mode", specPath.Child("prewarm"), mode))
+	}
+	if eifareplica.Spec.Prewarm != nil && eifareplica.Spec.LeadTime == nil {
+		warnings = append(warnings, fmt.Sprintf("%s only pre-warms the points of a timeline without %s",
+			specPath.Child("prewarm"), specPath.Child("leadTime")))
+	}
+
+	cron, err := cronexpr.Parse(eifareplica.Spec.Schedule)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("schedule"), eifareplica.Spec.Schedule, err.Error()))
+	}
+	if leadTime := eifareplica.Spec.LeadTime; leadTime != nil {
+		if leadTime.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("leadTime"), leadTime.String(), "must be greater than 0"))
+		} else if interval := shortestInterval(cron, time.Now()); interval > 0 && leadTime.Duration >= interval {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("leadTime"), leadTime.String(),
+				fmt.Sprintf("must be shorter than the interval of %s between two scheduled times", interval)))
+		}
+	}
+
+	jobPath := specPath.Child("jobTemplate", "spec")
+	jobSpec := eifareplica.Spec.JobTemplate.Spec
+	if len(jobSpec.Template.Spec.Containers) == 0 {
+		allErrs = append(allErrs, field.Required(jobPath.Child("template", "spec", "containers"),
+			"at least one container is required"))
+	}
+	// a pod restarted within its job would hide the failures of the job from the retries
+	if jobSpec.Template.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		allErrs = append(allErrs, field.NotSupported(jobPath.Child("template", "spec", "restartPolicy"),
+			jobSpec.Template.Spec.RestartPolicy, []string{string(corev1.RestartPolicyNever)}))
+	}
+	if jobSpec.ActiveDeadlineSeconds != nil && *jobSpec.ActiveDeadlineSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(jobPath.Child("activeDeadlineSeconds"), *jobSpec.ActiveDeadlineSeconds,
+			"must be greater than 0"))
+	}
+	if jobSpec.BackoffLimit != nil && *jobSpec.BackoffLimit < 0 {
+		allErrs = append(allErrs, field.Invalid(jobPath.Child("backoffLimit"), *jobSpec.BackoffLimit,
+			"must be greater than or equal to 0"))
+	}
+	if jobSpec.Completions != nil && *jobSpec.Completions != 1 {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored, the job always runs with 1 completion",
+			jobPath.Child("completions")))
+	}
+	if jobSpec.Parallelism != nil && *jobSpec.Parallelism != 1 {
+		warnings = append(warnings, fmt.Sprintf("%s is ignored, the job always runs with parallelism 1",
+			jobPath.Child("parallelism")))
+	}
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(
+		schema.GroupKind{Group: schedulev1.GroupVersion.Group, Kind: "EifaReplica"},
+		eifareplica.Name, allErrs)
+}
+
+// shortestInterval returns the shortest interval between the next scheduled times of cron, 0 when cron
+// is nil or schedules less than two times
+func shortestInterval(cron *cronexpr.Expression, now time.Time) time.Duration {
+	if cron == nil {
+		return 0
+	}
+	times := cron.NextN(now, 64)
+	var shortest time.Duration
+	for i := 1; i < len(times); i++ {
+		if interval := times[i].Sub(times[i-1]); shortest == 0 || interval < shortest {
+			shortest = interval
+		}
+	}
+	return shortest
+}
+
+// isAutoscalerKind reports whether kind refers to an autoscaler whose bounds are driven instead of replicas
+func isAutoscalerKind(kind string) bool {
+	switch strings.ToLower(kind) {
+	case "horizontalpodautoscaler", "hpa", "scaledobject":
+		return true
+	}
+	return false
+}
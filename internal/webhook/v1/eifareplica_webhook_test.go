@@ -0,0 +1,201 @@
+/*
+Copyright 2025 Erfan Mahvash.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulev1 "github.com/erfan-272758/eifa-replica-operator/api/v1"
+)
+
+var _ = Describe("EifaReplica Webhook", func() {
+	var (
+		obj       *schedulev1.EifaReplica
+		oldObj    *schedulev1.EifaReplica
+		validator EifaReplicaCustomValidator
+		defaulter EifaReplicaCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		obj = &schedulev1.EifaReplica{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-resource", Namespace: "default"},
+			Spec: schedulev1.EifaReplicaSpec{
+				ScaleTargetRef: schedulev1.ScaleTargetRef{Kind: "Deployment", Name: "nginx"},
+				MinReplicas:    1,
+				MaxReplicas:    5,
+				Schedule:       "*/5 * * * *",
+			},
+		}
+		obj.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{{Name: "job", Image: "busybox"}}
+		oldObj = obj.DeepCopy()
+		validator = EifaReplicaCustomValidator{}
+		Expect(validator).NotTo(BeNil(), "Expected validator to be initialized")
+		defaulter = EifaReplicaCustomDefaulter{}
+		Expect(defaulter).NotTo(BeNil(), "Expected defaulter to be initialized")
+		Expect(oldObj).NotTo(BeNil(), "Expected oldObj to be initialized")
+		Expect(obj).NotTo(BeNil(), "Expected obj to be initialized")
+	})
+
+	Context("When creating EifaReplica under Defaulting Webhook", func() {
+		It("Should persist the job defaults", func() {
+			By("calling the Default method to apply defaults")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			By("checking that the default values are set")
+			Expect(*obj.Spec.JobTemplate.Spec.ActiveDeadlineSeconds).To(Equal(schedulev1.DefaultActiveDeadlineSeconds))
+			Expect(*obj.Spec.JobTemplate.Spec.BackoffLimit).To(Equal(schedulev1.DefaultBackoffLimit))
+			Expect(obj.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		})
+
+		It("Should drive the minReplicas of an HPA by default", func() {
+			obj.Spec.ScaleTargetRef.Kind = "hpa"
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.ScaleTargetRef.Mode).To(Equal(schedulev1.MODE_MIN_REPLICAS))
+		})
+
+		It("Should keep values set by the user", func() {
+			deadline := int64(60)
+			obj.Spec.JobTemplate.Spec.ActiveDeadlineSeconds = &deadline
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(*obj.Spec.JobTemplate.Spec.ActiveDeadlineSeconds).To(Equal(deadline))
+		})
+	})
+
+	Context("When creating or updating EifaReplica under Validating Webhook", func() {
+		BeforeEach(func() {
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+		})
+
+		It("Should admit creation if all required fields are valid", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).To(BeNil())
+		})
+
+		It("Should deny creation if maxReplicas is not set", func() {
+			obj.Spec.MinReplicas = 0
+			obj.Spec.MaxReplicas = 0
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.maxReplicas"))
+		})
+
+		It("Should deny creation if minReplicas is greater than maxReplicas", func() {
+			obj.Spec.MinReplicas = 10
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.minReplicas"))
+		})
+
+		It("Should deny creation if an HPA target allows 0 replicas", func() {
+			obj.Spec.ScaleTargetRef.Kind = "HorizontalPodAutoscaler"
+			obj.Spec.MinReplicas = 0
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.minReplicas"))
+		})
+
+		It("Should deny creation if the schedule can not be parsed", func() {
+			obj.Spec.Schedule = "@every 5m"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.schedule"))
+		})
+
+		It("Should deny creation if the fallback is ambiguous", func() {
+			replicas := int32(3)
+			obj.Spec.Fallback = &schedulev1.Fallback{AfterConsecutiveFailures: 2, Replicas: &replicas, ScaleToMax: true}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fallback"))
+
+			obj.Spec.Fallback.ScaleToMax = false
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny creation if the max deviation factor is not greater than 1", func() {
+			obj.Spec.AnomalyDetection = &schedulev1.AnomalyDetection{HistorySize: 5, MaxDeviationFactor: "0.5"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("maxDeviationFactor"))
+		})
+
+		It("Should deny creation if the approval has no threshold", func() {
+			obj.Spec.Approval = &schedulev1.Approval{}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("approval"))
+		})
+
+		It("Should deny creation if the max hourly cost is not positive", func() {
+			maxCost := "0"
+			obj.Spec.MaxHourlyCost = &maxCost
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("maxHourlyCost"))
+		})
+
+		It("Should deny creation if the lead time spans a schedule interval", func() {
+			obj.Spec.LeadTime = &metav1.Duration{Duration: 2 * time.Minute}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			obj.Spec.LeadTime = &metav1.Duration{Duration: 5 * time.Minute}
+			_, err = validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("leadTime"))
+		})
+
+		It("Should deny creation if the job template has no containers", func() {
+			obj.Spec.JobTemplate.Spec.Template.Spec.Containers = nil
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.jobTemplate.spec.template.spec.containers"))
+		})
+
+		It("Should deny update if the restart policy is not Never", func() {
+			obj.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("restartPolicy"))
+
+			obj.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+			_, err = validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("restartPolicy"))
+		})
+
+		It("Should warn about overridden completions", func() {
+			completions := int32(3)
+			obj.Spec.JobTemplate.Spec.Completions = &completions
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+		})
+
+		It("Should warn about a ramp in Metric mode", func() {
+			obj.Spec.Mode = schedulev1.MODE_METRIC
+			obj.Spec.Behavior = &schedulev1.EifaReplicaBehavior{Ramp: &schedulev1.RampConfig{Steps: 5, DurationSeconds: 600}}
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+		})
+	})
+})